@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/gochi-demo/internal/app"
+	"github.com/gochi-demo/internal/config"
+	"github.com/gochi-demo/internal/export"
+	"github.com/gochi-demo/internal/importer"
+)
+
+// commands are the subcommands run instead of the server, as
+// "gochi-demo <name> [flags] [args]".
+var commands = map[string]func(a *app.App, router http.Handler, args []string) error{
+	"import":  runImport,
+	"export":  runExport,
+	"reindex": runReindex,
+}
+
+// tools are the subcommands needing neither the app nor its database, run
+// before either is set up.
+var tools = map[string]func(args []string) error{
+	"genkeys": runGenKeys,
+}
+
+// runImport imports a directory of Markdown posts with front matter.
+func runImport(a *app.App, _ http.Handler, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "report what would be imported without writing anything")
+	author := fs.String("author", "", "author of the posts whose front matter names none")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: gochi-demo import [-dry-run] [-author name] <content dir>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	report, err := importer.New(a, importer.Options{DryRun: *dryRun, Author: *author}).Run(context.Background(), fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	report.Write(os.Stdout)
+	if report.Failed() {
+		return fmt.Errorf("%d file(s) could not be imported", report.Count(importer.ActionFailed))
+	}
+	return nil
+}
+
+// runExport renders the public pages of the site into a directory, for
+// static hosting.
+func runExport(_ *app.App, router http.Handler, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: gochi-demo export <output dir>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	report, err := export.New(router).Run(context.Background(), fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	report.Write(os.Stdout)
+	return nil
+}
+
+// runReindex recomputes the search text of every post.
+func runReindex(a *app.App, _ http.Handler, args []string) error {
+	fs := flag.NewFlagSet("reindex", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: gochi-demo reindex")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	n, err := a.Reindex(context.Background())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Reindexed %d posts.\n", n)
+	return nil
+}
+
+// runGenKeys prints a SESSION_KEYS setting with a new key pair. With
+// -rotate the current pairs are kept after it, so sessions signed with
+// them stay valid until they expire.
+func runGenKeys(args []string) error {
+	fs := flag.NewFlagSet("genkeys", flag.ExitOnError)
+	rotate := fs.Bool("rotate", false, "keep the current SESSION_KEYS after the new pair")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: gochi-demo genkeys [-rotate]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	pair, err := config.GenerateSessionKeyPair()
+	if err != nil {
+		return err
+	}
+	if current := config.GetConfig("SESSION_KEYS"); *rotate && current != "" {
+		pair += "," + current
+	}
+	fmt.Println("SESSION_KEYS=" + pair)
+	return nil
+}
@@ -1,12 +1,13 @@
 package main
 
 import (
+	"context"
 	"embed"
-	"fmt"
 	"html/template"
 	"io/fs"
 	"log"
 	"net/http"
+	"os"
 	"strings"
 
 	"github.com/go-chi/chi/v5"
@@ -16,14 +17,12 @@ import (
 	"github.com/gochi-demo/internal/config"
 	"github.com/gochi-demo/internal/database"
 	"github.com/gochi-demo/internal/handlers"
+	"github.com/gochi-demo/internal/storage"
 	"github.com/gochi-demo/internal/web"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
 )
 
-// ─── TEMPLATE PARSER ─────────────────────────────────────────────────────────
-var templates = template.Must(template.ParseFS(web.FS, "templates/*.html"))
-
 // ─── STATIC FILE SERVER FOR EMBED FS ─────────────────────────────────────────
 func EmbeddedFileServer(r chi.Router, route string, fsys embed.FS) {
 	// Convert embed.FS → http.FileSystem
@@ -61,57 +60,9 @@ func ServeTemplatesAtRoot(r chi.Router, fsys embed.FS, subdir string) {
 
 // HTML ROUTE USING EMBEDDED TEMPLATES
 func HandleTemplates(r *chi.Mux) {
-	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
-		user, err := auth.GetUserFromSession(r)
-		// if err != nil {
-		// http.Error(w, err.Error(), http.StatusForbidden)
-		// http.Redirect(w, r, "/login", http.StatusSeeOther)
-		// }
-
-		var firstname string
-		if err == nil {
-			firstname = user.FirstName
-		}
-
-		data := map[string]any{
-			"Title":    "Hello from Go Embedded Templates!",
-			"Msg":      "This is rendered from template files bundled inside the binary.",
-			"Username": firstname,
-		}
-
-		err = templates.ExecuteTemplate(w, "index.html", data)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-		}
-	})
-
+	// Posts are listed on the home page
 	r.Get("/posts", func(w http.ResponseWriter, r *http.Request) {
-		user, err := auth.GetUserFromSession(r)
-
-		var firstname string
-		if err == nil {
-			firstname = user.FirstName
-		}
-
-		data := map[string]any{
-			"Title":    "Hello from Go Embedded Templates!",
-			"Msg":      "This is rendered from template files bundled inside the binary.",
-			"Username": firstname,
-			"Date":     "2025-12-10",
-			"Tags":     []string{"cat1", "cat2"},
-			"Content":  "This is rendered from template files bundled inside the binary.",
-			"PrevPost": map[string]any{
-				"URL": "https://google.com/prev",
-			},
-			"NextPost": map[string]any{
-				"URL": "https://google.com/next",
-			},
-		}
-
-		err = templates.ExecuteTemplate(w, "post.html", data)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-		}
+		http.Redirect(w, r, "/", http.StatusMovedPermanently)
 	})
 }
 
@@ -120,19 +71,23 @@ func HandleTemplates(r *chi.Mux) {
 //
 
 func main() {
+	if len(os.Args) > 1 {
+		if run, ok := tools[os.Args[1]]; ok {
+			if err := run(os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+	}
+
 	// Open database using sqlx
 	// db, err := sqlx.Open("sqlite", "app.db")
-	db, err := sqlx.Open("sqlite", "file:app.db?cache=shared&mode=rwc")
+	db, err := sqlx.Open("sqlite", "file:app.db?cache=shared&mode=rwc&_time_format=sqlite&_pragma=foreign_keys(1)")
 	if err != nil {
 		log.Fatal(err)
 	}
 	defer db.Close()
 
-	clientID := config.GetConfig("CLIENT_ID")
-	fmt.Println("ClientID: ", clientID)
-	fmt.Println("ClientSecret: ", config.GetConfig("CLIENT_SECRET"))
-	fmt.Println("ClientCallbackURL: ", config.GetConfig("CLIENT_CALLBACK_URL"))
-
 	enablePg := strings.ToUpper(config.GetConfig("ENABLE_PG")) == "TRUE"
 
 	var a *app.App
@@ -146,23 +101,79 @@ func main() {
 		defer pgdb.Close()
 
 		a = &app.App{
-			Users:   database.NewSQLiteUserStore(db),
-			PgUsers: *database.NewPgUserStore(pgdb),
+			Users:     database.NewSQLiteUserStore(db),
+			Accounts:  database.NewPgAccountStore(pgdb),
+			Sessions:  database.NewPgSessionStore(pgdb),
+			PgUsers:   *database.NewPgUserStore(pgdb),
+			Posts:     database.NewPgPostStore(pgdb),
+			Revisions: database.NewPgRevisionStore(pgdb),
+			Media:     database.NewPgMediaStore(pgdb),
+			Comments:  database.NewPgCommentStore(pgdb),
+			Series:    database.NewPgSeriesStore(pgdb),
+			Related:   database.NewPgRelatedStore(pgdb),
+			Search:    database.NewPgSearchStore(pgdb),
 		}
 
 		database.InitPgDB(pgdb)
 	} else {
 		a = &app.App{
-			Users: database.NewSQLiteUserStore(db),
+			Users:     database.NewSQLiteUserStore(db),
+			Accounts:  database.NewSQLiteAccountStore(db),
+			Sessions:  database.NewSQLiteSessionStore(db),
+			Posts:     database.NewSQLitePostStore(db),
+			Revisions: database.NewSQLiteRevisionStore(db),
+			Media:     database.NewSQLiteMediaStore(db),
+			Comments:  database.NewSQLiteCommentStore(db),
+			Series:    database.NewSQLiteSeriesStore(db),
+			Related:   database.NewSQLiteRelatedStore(db),
+			Search:    database.NewSQLiteSearchStore(db),
+		}
+	}
+
+	a.Blobs, err = storage.FromConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	app.LoadPreviewKey()
+
+	database.InitSqliteDB(db)
+	if err := a.SeedPosts(context.Background()); err != nil {
+		log.Fatal(err)
+	}
+
+	// Template helpers need the app, so templates are parsed once it exists.
+	templates := web.MustParseTemplates(template.FuncMap{
+		"responsiveImages": a.ResponsiveImages,
+		"tagURL":           database.TagURL,
+	})
+	r := newRouter(a, templates)
+
+	if len(os.Args) > 1 {
+		run, ok := commands[os.Args[1]]
+		if !ok {
+			log.Fatalf("unknown command %q", os.Args[1])
+		}
+		if err := run(a, r, os.Args[2:]); err != nil {
+			log.Fatal(err)
 		}
+		return
 	}
 
+	a.StartScheduler(context.Background())
+	a.StartRelated(context.Background())
+	a.StartSessionSweep(context.Background())
+
+	http.ListenAndServe(":10000", r)
+}
+
+// newRouter mounts every route of the site on a new router.
+func newRouter(a *app.App, templates *web.Templates) *chi.Mux {
 	r := chi.NewRouter()
 
 	// Middlewares
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
-	auth.NewAuth(r)
+	auth.NewAuth(r, a.Accounts, a.Sessions)
 
 	// Serve embedded static files
 	EmbeddedFileServer(r, "/", web.FS)
@@ -175,6 +186,55 @@ func main() {
 	h := handlers.NewUserHandler(a)
 	r.Get("/users/{id}", h.GetUser)
 	r.Get("/users/sqlite/{id}", h.GetSqliteUser)
+
+	ph := handlers.NewPostHandler(a, templates)
+	r.Get("/", ph.Index)
+	r.Get("/posts/{slug}", ph.GetPost)
+	r.With(auth.RequireAuth).Get("/posts/{slug}/preview", ph.Preview)
+	r.Get("/posts/{slug}/card.png", ph.SocialCard)
+	r.Get("/api/posts", ph.ListPostsJSON)
+	r.Get("/api/posts/{slug}", ph.GetPostJSON)
+	r.Get("/api/posts/{slug}/related", ph.RelatedJSON)
+
+	ch := handlers.NewCommentHandler(a)
+	r.Post("/posts/{slug}/comments", ch.PostComment)
+
+	th := handlers.NewTagHandler(a, templates)
+	r.Get("/tags", th.ListTags)
+	r.Get("/tags/{tag}", th.GetTag)
+
+	seh := handlers.NewSeriesHandler(a, templates)
+	r.Get("/series/{slug}", seh.GetSeries)
+
+	arh := handlers.NewArchiveHandler(a, templates)
+	r.Get("/archive", arh.Archive)
+	r.Get("/{year:[0-9]{4}}", arh.Year)
+	r.Get("/{year:[0-9]{4}}/{month:[0-9]{2}}", arh.Month)
+
+	fh := handlers.NewFeedHandler(a)
+	r.Get("/rss.xml", fh.RSS)
+	r.Get("/atom.xml", fh.Atom)
+	r.Get("/feed.json", fh.JSON)
+	r.Get("/tags/{tag}/feed.xml", fh.TagRSS)
+
+	sh := handlers.NewSitemapHandler(a)
+	r.Get("/sitemap.xml", sh.Sitemap)
+	r.Get("/sitemap-{n}.xml", sh.SitemapPart)
+	r.Get("/robots.txt", sh.Robots)
+
+	srh := handlers.NewSearchHandler(a, templates)
+	r.Get("/search", srh.Search)
+	r.Get("/api/search", srh.SearchJSON)
+
+	mh := handlers.NewMediaHandler(a)
+	r.Get("/media/*", mh.Serve)
+
+	ach := handlers.NewAccountHandler(a, templates)
+	r.With(auth.RequireAuth).Route("/account", ach.Routes)
+
+	ah := handlers.NewAdminHandler(a, templates)
+	r.With(auth.RequireAuth, auth.RequireAdmin).Route("/admin", ah.Routes)
+
 	handlers.InitTestHandler(r)
 
 	// Serve template files from templates/ folder at root path "/"
@@ -182,7 +242,5 @@ func main() {
 	// This catch-all should be registered last so specific routes take precedence
 	// ServeTemplatesAtRoot(r, web.FS, "templates")
 
-	database.InitSqliteDB(db)
-
-	http.ListenAndServe(":10000", r)
+	return r
 }
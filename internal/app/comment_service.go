@@ -0,0 +1,43 @@
+package app
+
+import (
+	"context"
+
+	"github.com/gochi-demo/internal/database"
+	"github.com/gochi-demo/internal/render"
+)
+
+// MaxCommentLength is the longest comment accepted, in runes.
+const MaxCommentLength = 5000
+
+// CommentThreads returns the approved comments of a post as threads: the
+// top level comments, oldest first, each holding its Replies. Replies to a
+// comment that is no longer shown move to the top level. The second result
+// is the number of comments.
+func (a *App) CommentThreads(ctx context.Context, postID int64) ([]*database.Comment, int, error) {
+	comments, err := a.Comments.ListApproved(ctx, postID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	byID := make(map[int64]*database.Comment, len(comments))
+	for i := range comments {
+		c := &comments[i]
+		if c.HTML, err = render.Comment(c.Body); err != nil {
+			return nil, 0, err
+		}
+		byID[c.ID] = c
+	}
+
+	var threads []*database.Comment
+	for i := range comments {
+		c := &comments[i]
+		if c.ParentID != nil && byID[*c.ParentID] != nil {
+			parent := byID[*c.ParentID]
+			parent.Replies = append(parent.Replies, c)
+			continue
+		}
+		threads = append(threads, c)
+	}
+	return threads, len(comments), nil
+}
@@ -0,0 +1,190 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html/template"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gochi-demo/internal/database"
+	"github.com/gochi-demo/internal/imaging"
+)
+
+// UploadTypes maps the content types accepted for uploads to the extension
+// they are stored with. SVG is left out on purpose: it can carry scripts.
+var UploadTypes = map[string]string{
+	"image/png":       ".png",
+	"image/jpeg":      ".jpg",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+}
+
+// ErrInvalidUpload is returned by StoreUpload for files that don't hold
+// what their content type says.
+var ErrInvalidUpload = errors.New("invalid upload")
+
+// imageSizes is the sizes attribute of post images: the full viewport on
+// small screens, the width of the content column otherwise.
+const imageSizes = "(max-width: 768px) 100vw, 768px"
+
+// StoreUpload saves an uploaded file of the given content type, one of
+// UploadTypes, to the blob store and records it. Images are stripped of
+// their metadata and get resized variants, see imaging.Process.
+func (a *App) StoreUpload(ctx context.Context, data []byte, contentType, uploadedBy string) (*database.Media, error) {
+	ext, ok := UploadTypes[contentType]
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported content type %s", ErrInvalidUpload, contentType)
+	}
+
+	base := "uploads/" + time.Now().UTC().Format("2006/01") + "/" + randomName()
+	return a.storeMedia(ctx, base, ext, data, contentType, uploadedBy)
+}
+
+// StoreImport is StoreUpload for files brought in by the importer. They
+// are stored under ImportKey, so importing the same file twice reuses the
+// first copy.
+func (a *App) StoreImport(ctx context.Context, data []byte, contentType, uploadedBy string) (*database.Media, error) {
+	key, err := ImportKey(data, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := a.Media.ListByKeys(ctx, []string{key})
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		return &existing[0], nil
+	}
+
+	ext := UploadTypes[contentType]
+	return a.storeMedia(ctx, strings.TrimSuffix(key, ext), ext, data, contentType, uploadedBy)
+}
+
+// ImportKey returns the key StoreImport saves data under, derived from
+// its content.
+func ImportKey(data []byte, contentType string) (string, error) {
+	ext, ok := UploadTypes[contentType]
+	if !ok {
+		return "", fmt.Errorf("%w: unsupported content type %s", ErrInvalidUpload, contentType)
+	}
+	sum := sha256.Sum256(data)
+	return "imports/" + hex.EncodeToString(sum[:16]) + ext, nil
+}
+
+// storeMedia stores data under base+ext, its variants under base with
+// their width appended, and records it all.
+func (a *App) storeMedia(ctx context.Context, base, ext string, data []byte, contentType, uploadedBy string) (*database.Media, error) {
+	m := &database.Media{Key: base + ext, ContentType: contentType, UploadedBy: uploadedBy}
+
+	if !strings.HasPrefix(contentType, "image/") {
+		m.Size = int64(len(data))
+		if err := a.putBlob(ctx, m.Key, data, contentType); err != nil {
+			return nil, err
+		}
+		return m, a.Media.Create(ctx, m)
+	}
+
+	original, variants, err := imaging.Process(data, contentType, imaging.Widths)
+	if errors.Is(err, imaging.ErrTooLarge) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidUpload, err)
+	}
+
+	m.Size = int64(len(original.Data))
+	m.Width, m.Height = original.Width, original.Height
+	if err := a.putBlob(ctx, m.Key, original.Data, contentType); err != nil {
+		return nil, err
+	}
+
+	for _, v := range variants {
+		key := base + "-" + strconv.Itoa(v.Width) + "w" + UploadTypes[v.ContentType]
+		if err := a.putBlob(ctx, key, v.Data, v.ContentType); err != nil {
+			return nil, err
+		}
+		m.Variants = append(m.Variants, database.MediaVariant{Key: key, Width: v.Width, Height: v.Height})
+	}
+
+	return m, a.Media.Create(ctx, m)
+}
+
+func (a *App) putBlob(ctx context.Context, key string, data []byte, contentType string) error {
+	return a.Blobs.Put(ctx, key, bytes.NewReader(data), int64(len(data)), contentType)
+}
+
+// randomName returns a random file name, so stored files never collide
+// and can be cached forever.
+func randomName() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+var (
+	imgTagPattern = regexp.MustCompile(`<img\s[^>]*>`)
+	mediaSrc      = regexp.MustCompile(`\ssrc="/media/([^"]+)"`)
+)
+
+// ResponsiveImages is the "responsiveImages" template helper. It adds the
+// dimensions of the uploaded images of content, rendered post HTML, and a
+// srcset listing their variants, so browsers download the smallest one
+// that fits.
+func (a *App) ResponsiveImages(content template.HTML) template.HTML {
+	html := string(content)
+
+	var keys []string
+	for _, tag := range imgTagPattern.FindAllString(html, -1) {
+		if m := mediaSrc.FindStringSubmatch(tag); m != nil {
+			keys = append(keys, m[1])
+		}
+	}
+	if len(keys) == 0 {
+		return content
+	}
+
+	media, err := a.Media.ListByKeys(context.Background(), keys)
+	if err != nil {
+		// The images still show, only without their variants.
+		return content
+	}
+	byKey := make(map[string]*database.Media, len(media))
+	for i := range media {
+		byKey[media[i].Key] = &media[i]
+	}
+
+	html = imgTagPattern.ReplaceAllStringFunc(html, func(tag string) string {
+		m := mediaSrc.FindStringSubmatch(tag)
+		if m == nil || byKey[m[1]] == nil || byKey[m[1]].Width == 0 {
+			return tag
+		}
+		return strings.TrimSuffix(tag, ">") + imageAttrs(byKey[m[1]]) + ">"
+	})
+	return template.HTML(html)
+}
+
+// imageAttrs returns the attributes added to the <img> tag of m.
+func imageAttrs(m *database.Media) string {
+	attrs := fmt.Sprintf(` width="%d" height="%d" loading="lazy" decoding="async"`, m.Width, m.Height)
+	if len(m.Variants) == 0 {
+		return attrs
+	}
+
+	srcset := make([]string, 0, len(m.Variants)+1)
+	for _, v := range m.Variants {
+		srcset = append(srcset, fmt.Sprintf("%s %dw", v.URL(), v.Width))
+	}
+	srcset = append(srcset, fmt.Sprintf("%s %dw", m.URL(), m.Width))
+
+	return attrs + ` srcset="` + template.HTMLEscapeString(strings.Join(srcset, ", ")) + `" sizes="` + imageSizes + `"`
+}
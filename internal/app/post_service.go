@@ -0,0 +1,80 @@
+package app
+
+import (
+	"context"
+
+	"github.com/gochi-demo/internal/config"
+	"github.com/gochi-demo/internal/database"
+	"github.com/gochi-demo/internal/render"
+)
+
+// RenderPost renders the Markdown body of p and fills in its word count,
+// reading time and summary.
+func (a *App) RenderPost(p *database.Post) (*render.Document, error) {
+	doc, err := render.Markdown(p.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	wpm := config.GetIntConfigWithDefault("READING_WPM", database.DefaultWordsPerMinute)
+	p.SetReadingStats(doc.WordCount, wpm)
+	p.Summary = doc.Summary
+
+	return doc, nil
+}
+
+// CreatePost saves the new post p, along with the text search indexes.
+func (a *App) CreatePost(ctx context.Context, p *database.Post, savedBy string) error {
+	p.SearchText = searchText(p.Body)
+	return a.Posts.Create(ctx, p, savedBy)
+}
+
+// UpdatePost saves p, along with the text search indexes.
+func (a *App) UpdatePost(ctx context.Context, p *database.Post, savedBy string) error {
+	p.SearchText = searchText(p.Body)
+	return a.Posts.Update(ctx, p, savedBy)
+}
+
+// SeedPosts gives an empty database the sample posts.
+func (a *App) SeedPosts(ctx context.Context) error {
+	n, err := a.Posts.CountAll(ctx)
+	if err != nil || n > 0 {
+		return err
+	}
+	for _, p := range samplePosts() {
+		if err := a.CreatePost(ctx, &p, p.Author); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reindex recomputes the search text of every post and returns how many
+// there were, for databases written before it existed or by an older
+// renderer.
+func (a *App) Reindex(ctx context.Context) (int, error) {
+	n, err := a.Posts.CountAll(ctx)
+	if err != nil {
+		return 0, err
+	}
+	posts, err := a.Posts.ListAll(ctx, n, 0)
+	if err != nil {
+		return 0, err
+	}
+	for _, p := range posts {
+		if err := a.Search.SetSearchText(ctx, p.ID, searchText(p.Body)); err != nil {
+			return 0, err
+		}
+	}
+	return len(posts), nil
+}
+
+// searchText returns the text of a post body that gets indexed and
+// snippeted, so results don't show its Markdown syntax.
+func searchText(body string) string {
+	text, err := render.Text(body)
+	if err != nil {
+		return body
+	}
+	return text
+}
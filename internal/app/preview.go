@@ -0,0 +1,79 @@
+package app
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gochi-demo/internal/config"
+	"github.com/gochi-demo/internal/database"
+)
+
+// PreviewTTL is how long a preview link stays valid.
+const PreviewTTL = 7 * 24 * time.Hour
+
+var (
+	previewOnce   sync.Once
+	previewSecret []byte
+)
+
+// LoadPreviewKey loads the key preview tokens are signed with. Outside of
+// development it exits when PREVIEW_SECRET is not set, so call it at
+// startup rather than on the first preview.
+func LoadPreviewKey() {
+	previewKey()
+}
+
+// previewKey returns the key preview tokens are signed with, PREVIEW_SECRET
+// or, in development, a random key that lasts until the next restart.
+func previewKey() []byte {
+	previewOnce.Do(func() {
+		if s := config.GetConfig("PREVIEW_SECRET"); s != "" {
+			previewSecret = []byte(s)
+			return
+		}
+		if config.Environment() != config.EnvDevelopment {
+			log.Fatal("PREVIEW_SECRET is not set; set it to a long random string")
+		}
+		log.Println("PREVIEW_SECRET is not set, preview links won't survive a restart")
+		previewSecret = make([]byte, 32)
+		rand.Read(previewSecret)
+	})
+	return previewSecret
+}
+
+// PreviewURL returns a link to the preview of p, valid for PreviewTTL.
+func (a *App) PreviewURL(p *database.Post) string {
+	expires := time.Now().Add(PreviewTTL).Unix()
+	return p.URL() + "/preview?token=" + url.QueryEscape(previewToken(p.Slug, expires))
+}
+
+// VerifyPreview reports whether token is an unexpired preview token for
+// the post with the given slug.
+func (a *App) VerifyPreview(slug, token string) bool {
+	exp, _, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+	expires, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return false
+	}
+	return hmac.Equal([]byte(token), []byte(previewToken(slug, expires)))
+}
+
+// previewToken signs slug and the expiry time, which the token carries in
+// clear as its first part.
+func previewToken(slug string, expires int64) string {
+	exp := strconv.FormatInt(expires, 10)
+	mac := hmac.New(sha256.New, previewKey())
+	mac.Write([]byte(slug + "\n" + exp))
+	return exp + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
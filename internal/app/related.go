@@ -0,0 +1,90 @@
+package app
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gochi-demo/internal/config"
+	"github.com/gochi-demo/internal/database"
+	"github.com/gochi-demo/internal/related"
+)
+
+// RelatedLimit is the number of related posts kept for every post.
+const RelatedLimit = 5
+
+// defaultRelatedInterval is how often, in seconds, related posts are
+// recomputed when RELATED_INTERVAL is not set.
+const defaultRelatedInterval = 3600
+
+// StartRelated computes the related posts of every published post in the
+// background, then again every RELATED_INTERVAL seconds and whenever
+// RefreshRelated is called, until ctx is done.
+func (a *App) StartRelated(ctx context.Context) {
+	seconds := config.GetIntConfigWithDefault("RELATED_INTERVAL", defaultRelatedInterval)
+	if seconds < 1 {
+		seconds = defaultRelatedInterval
+	}
+
+	a.relatedRefresh = make(chan struct{}, 1)
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(seconds) * time.Second)
+		defer ticker.Stop()
+
+		for {
+			if err := a.computeRelated(ctx); err != nil {
+				log.Println("related:", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-a.relatedRefresh:
+			}
+		}
+	}()
+}
+
+// RefreshRelated asks for the related posts to be recomputed, after posts
+// were written. It doesn't wait for the work to be done.
+func (a *App) RefreshRelated() {
+	if a.relatedRefresh == nil {
+		return
+	}
+	select {
+	case a.relatedRefresh <- struct{}{}:
+	default:
+	}
+}
+
+func (a *App) computeRelated(ctx context.Context) error {
+	total, err := a.Posts.Count(ctx)
+	if err != nil {
+		return err
+	}
+	posts, err := a.Posts.List(ctx, total, 0)
+	if err != nil {
+		return err
+	}
+
+	docs := make([]related.Doc, len(posts))
+	for i, p := range posts {
+		docs[i] = related.Doc{
+			ID:   p.ID,
+			Tags: p.Tags,
+			// The title is repeated to weigh more than the body.
+			Text: strings.Join([]string{p.Title, p.Title, strings.Join(p.Tags, " "), p.Body}, "\n"),
+		}
+	}
+
+	var rows []database.RelatedPost
+	for id, matches := range related.Compute(docs, RelatedLimit) {
+		for _, m := range matches {
+			rows = append(rows, database.RelatedPost{PostID: id, RelatedID: m.ID, Score: m.Score})
+		}
+	}
+	return a.Related.ReplaceRelated(ctx, rows)
+}
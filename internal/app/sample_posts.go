@@ -0,0 +1,86 @@
+package app
+
+import (
+	"time"
+
+	"github.com/gochi-demo/internal/database"
+)
+
+// samplePosts are the posts of a fresh database, so it has something to
+// render.
+func samplePosts() []database.Post {
+	return []database.Post{
+		{
+			Slug:        "modern-css-techniques",
+			Title:       "Modern CSS Techniques",
+			Tags:        []string{"css", "frontend"},
+			PublishedAt: time.Date(2025, 12, 1, 9, 0, 0, 0, time.UTC),
+			Author:      "AstroPaper",
+			Body: `Explore the latest CSS features and how they can improve your workflow.
+From container queries to cascade layers, CSS has never been more powerful.
+
+## Container queries
+
+Container queries let a component adapt to the space it is given instead of the viewport.
+
+` + "```css" + `
+.card-list {
+  container-type: inline-size;
+}
+
+@container (min-width: 40rem) {
+  .card { display: grid; grid-template-columns: 1fr 2fr; }
+}
+` + "```" + `
+
+## Cascade layers
+
+Layers make the order of your styles explicit, so utility classes always win over the base theme.
+`,
+		},
+		{
+			Slug:        "building-accessible-websites",
+			Title:       "Building Accessible Websites",
+			Tags:        []string{"accessibility", "web-dev"},
+			PublishedAt: time.Date(2025, 12, 5, 9, 0, 0, 0, time.UTC),
+			Author:      "AstroPaper",
+			Body: `Accessibility is crucial for creating inclusive web experiences.
+Here are some best practices to make your website accessible to everyone.
+
+## Use semantic HTML
+
+Headings, lists, buttons and landmarks give assistive technology the structure of the page for free.
+
+## Mind the contrast
+
+Text should keep a contrast ratio of at least 4.5:1 against its background, in both light and dark mode.
+
+## Keep it keyboard friendly
+
+Every interactive element must be reachable with the Tab key and show a visible focus ring.
+`,
+		},
+		{
+			Slug:        "getting-started-with-astropaper",
+			Title:       "Getting Started with AstroPaper Theme",
+			Tags:        []string{"astro", "tutorial"},
+			PublishedAt: time.Date(2025, 12, 8, 9, 0, 0, 0, time.UTC),
+			Author:      "AstroPaper",
+			Body: `Learn how to set up and customize the AstroPaper theme for your blog.
+This guide covers installation, configuration, and customization options.
+
+## Installation
+
+Create a new project from the template:
+
+` + "```bash" + `
+npm create astro@latest -- --template satnaing/astro-paper
+` + "```" + `
+
+## Configuration
+
+Site wide settings such as the title, the author and the number of posts per page live in ` + "`src/config.ts`" + `.
+`,
+		},
+	}
+}
@@ -0,0 +1,49 @@
+package app
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/gochi-demo/internal/config"
+)
+
+// defaultSchedulerInterval is how often, in seconds, scheduled posts are
+// checked when SCHEDULER_INTERVAL is not set.
+const defaultSchedulerInterval = 60
+
+// StartScheduler publishes scheduled posts whose time has come, checking
+// every SCHEDULER_INTERVAL seconds until ctx is done.
+func (a *App) StartScheduler(ctx context.Context) {
+	seconds := config.GetIntConfigWithDefault("SCHEDULER_INTERVAL", defaultSchedulerInterval)
+	if seconds < 1 {
+		seconds = defaultSchedulerInterval
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(seconds) * time.Second)
+		defer ticker.Stop()
+
+		for {
+			a.publishDue(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (a *App) publishDue(ctx context.Context) {
+	n, err := a.Posts.PublishDue(ctx, time.Now())
+	if err != nil {
+		log.Println("scheduler:", err)
+		return
+	}
+	if n > 0 {
+		log.Printf("scheduler: published %d scheduled post(s)", n)
+		a.RefreshRelated()
+	}
+}
@@ -0,0 +1,48 @@
+package app
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/gochi-demo/internal/config"
+)
+
+// defaultSessionSweepInterval is how often, in seconds, expired sessions
+// are deleted when SESSION_SWEEP_INTERVAL is not set.
+const defaultSessionSweepInterval = 3600
+
+// StartSessionSweep deletes expired sessions every SESSION_SWEEP_INTERVAL
+// seconds until ctx is done.
+func (a *App) StartSessionSweep(ctx context.Context) {
+	seconds := config.GetIntConfigWithDefault("SESSION_SWEEP_INTERVAL", defaultSessionSweepInterval)
+	if seconds < 1 {
+		seconds = defaultSessionSweepInterval
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(seconds) * time.Second)
+		defer ticker.Stop()
+
+		for {
+			a.sweepSessions(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (a *App) sweepSessions(ctx context.Context) {
+	n, err := a.Sessions.DeleteExpiredSessions(ctx, time.Now())
+	if err != nil {
+		log.Println("sessions:", err)
+		return
+	}
+	if n > 0 {
+		log.Printf("sessions: deleted %d expired session(s)", n)
+	}
+}
@@ -0,0 +1,38 @@
+package app
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+
+// Slugify turns a title into a URL slug: lowercase ASCII letters and
+// digits separated by single dashes.
+func Slugify(title string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range norm.NFKD.String(strings.ToLower(title)) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			if dash && sb.Len() > 0 {
+				sb.WriteByte('-')
+			}
+			sb.WriteRune(r)
+			dash = false
+		case unicode.Is(unicode.Mn, r):
+			// Accents left over by the decomposition: "é" becomes "e".
+		default:
+			dash = true
+		}
+	}
+	return sb.String()
+}
+
+// ValidSlug reports whether slug is in the form produced by Slugify.
+func ValidSlug(slug string) bool {
+	return slugPattern.MatchString(slug)
+}
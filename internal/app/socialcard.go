@@ -0,0 +1,79 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gochi-demo/internal/config"
+	"github.com/gochi-demo/internal/database"
+	"github.com/gochi-demo/internal/socialcard"
+)
+
+// SocialCardVersion returns what changes whenever the social card of p
+// has to be drawn again: the id of its latest revision, or the time it was
+// last updated for a post without revisions.
+func (a *App) SocialCardVersion(ctx context.Context, p *database.Post) (int64, error) {
+	id, err := a.Revisions.LatestRevisionID(ctx, p.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return p.UpdatedAt.Unix(), nil
+	}
+	return id, err
+}
+
+// SocialCard returns the path of the PNG social card of p, drawing it
+// first unless a card for its latest revision is already cached in
+// SOCIAL_CARD_DIR. Cards of older revisions are removed.
+func (a *App) SocialCard(ctx context.Context, p *database.Post) (string, error) {
+	version, err := a.SocialCardVersion(ctx, p)
+	if err != nil {
+		return "", err
+	}
+
+	dir := config.GetConfigWithDefault("SOCIAL_CARD_DIR", "cards")
+	name := filepath.Join(dir, fmt.Sprintf("%d-%d.png", p.ID, version))
+	if _, err := os.Stat(name); err == nil {
+		return name, nil
+	}
+
+	data, err := socialcard.Draw(socialcard.Card{
+		Title: p.Title,
+		Date:  p.PublishedAt.Format("January 2, 2006"),
+		Site:  config.GetSite().Title,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	// Concurrent requests may draw the same card; the rename makes sure
+	// none of them serves a partly written file.
+	tmp, err := os.CreateTemp(dir, ".card-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		return "", err
+	}
+
+	old, _ := filepath.Glob(filepath.Join(dir, fmt.Sprintf("%d-*.png", p.ID)))
+	for _, f := range old {
+		if f != name {
+			os.Remove(f)
+		}
+	}
+	return name, nil
+}
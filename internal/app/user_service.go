@@ -1,8 +1,23 @@
 package app
 
-import "github.com/gochi-demo/internal/database"
+import (
+	"github.com/gochi-demo/internal/database"
+	"github.com/gochi-demo/internal/storage"
+)
 
 type App struct {
-	Users   database.UserStore
-	PgUsers database.PgUserStore
+	Users     database.UserStore
+	Accounts  database.AccountStore
+	Sessions  database.SessionStore
+	PgUsers   database.PgUserStore
+	Posts     database.PostStore
+	Revisions database.RevisionStore
+	Search    database.SearchStore
+	Media     database.MediaStore
+	Comments  database.CommentStore
+	Series    database.SeriesStore
+	Related   database.RelatedStore
+	Blobs     storage.BlobStore
+
+	relatedRefresh chan struct{}
 }
@@ -5,38 +5,44 @@ import (
 	"encoding/json"
 	"fmt"
 	"html/template"
+	"log"
 	"net/http"
-	"sort"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/gochi-demo/internal/config"
+	"github.com/gochi-demo/internal/database"
+	"github.com/gorilla/securecookie"
 	"github.com/gorilla/sessions"
 	"github.com/markbates/goth"
 	"github.com/markbates/goth/gothic"
-	"github.com/markbates/goth/providers/google"
 )
 
 const (
-	key            = "somerandomkey12345678901234567890" // Must be 32 bytes for AES-256
 	maxAge         = 86400 * 30
-	isProd         = false
+	oauthMaxAge    = 10 * 60
 	SessionName    = "user-session"
 	UserSessionKey = "user"
+
+	// Session keys of the OAuth tokens of the signed in user. Sessions are
+	// kept server-side, so the tokens never reach the browser.
+	accessTokenKey  = "access_token"
+	refreshTokenKey = "refresh_token"
 )
 
-// User represents the authenticated user data we store in session
+// User is the signed in user. The session only holds the id of their
+// account, which is loaded again on each request.
 type User struct {
-	ID           string
-	Email        string
-	Name         string
-	FirstName    string
-	LastName     string
-	AvatarURL    string
-	Provider     string
-	AccessToken  string
-	RefreshToken string
+	ID        int64
+	Email     string
+	Name      string
+	FirstName string
+	LastName  string
+	AvatarURL string
 }
 
+// ProviderIndex lists the enabled providers, in AUTH_PROVIDERS order, and
+// maps them to their label.
 type ProviderIndex struct {
 	Providers    []string
 	ProvidersMap map[string]string
@@ -44,6 +50,8 @@ type ProviderIndex struct {
 
 var indexTemplate = `{{range $key,$value:=.Providers}}
     <p><a href="/auth/{{$value}}">Log in with {{index $.ProvidersMap $value}}</a></p>
+{{else}}
+    <p>No sign in provider is configured.</p>
 {{end}}`
 
 var userTemplate = `
@@ -60,7 +68,10 @@ var userTemplate = `
 <p>RefreshToken: {{.RefreshToken}}</p>
 `
 
-var store *sessions.CookieStore
+var store *DBStore
+
+// accounts is where users are recorded when they sign in.
+var accounts database.AccountStore
 
 // GetProvider is a middleware that extracts the provider from Chi URL params
 // and adds it to the request context for Gothic to use
@@ -99,54 +110,139 @@ func RequireAuth(next http.Handler) http.Handler {
 	})
 }
 
-// SaveUserToSession saves the user data to the session
+// IsAdmin reports whether user may use the admin area. Admins are listed
+// in ADMIN_IDENTITIES, separated by commas, by the provider:user id of an
+// identity as shown on /profile. Emails aren't trusted for this, as not
+// every provider verifies them.
+func IsAdmin(ctx context.Context, user *User) bool {
+	if user == nil {
+		return false
+	}
+	admins := map[string]bool{}
+	for _, key := range strings.Split(config.GetConfig("ADMIN_IDENTITIES"), ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			admins[key] = true
+		}
+	}
+	if len(admins) == 0 {
+		return false
+	}
+
+	ids, err := accounts.ListIdentities(ctx, user.ID)
+	if err != nil {
+		log.Println("auth:", err)
+		return false
+	}
+	for _, id := range ids {
+		if admins[identityKey(id)] {
+			return true
+		}
+	}
+	return false
+}
+
+// identityKey names an identity in ADMIN_IDENTITIES.
+func identityKey(id database.Identity) string {
+	return id.Provider + ":" + id.ProviderUserID
+}
+
+// RequireAdmin is a middleware that only lets admins through. It must come
+// after RequireAuth, which puts the user in the context.
+func RequireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, err := GetUserFromContext(r)
+		if err != nil || !IsAdmin(r.Context(), user) {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// SaveUserToSession records the sign in of gothUser in their account and
+// puts the account id in the session.
 func SaveUserToSession(w http.ResponseWriter, r *http.Request, gothUser goth.User) error {
 	session, err := store.Get(r, SessionName)
 	if err != nil {
 		return err
 	}
 
-	user := &User{
-		ID:           gothUser.UserID,
-		Email:        gothUser.Email,
-		Name:         gothUser.Name,
-		FirstName:    gothUser.FirstName,
-		LastName:     gothUser.LastName,
-		AvatarURL:    gothUser.AvatarURL,
-		Provider:     gothUser.Provider,
-		AccessToken:  gothUser.AccessToken,
-		RefreshToken: gothUser.RefreshToken,
+	// Some providers, GitHub among them, only give a full name.
+	firstName := gothUser.FirstName
+	if firstName == "" {
+		if fields := strings.Fields(gothUser.Name); len(fields) > 0 {
+			firstName = fields[0]
+		} else {
+			firstName = gothUser.NickName
+		}
 	}
 
-	// Serialize user to JSON
-	userData, err := json.Marshal(user)
+	account, err := accounts.UpsertLogin(r.Context(), &database.Identity{
+		Provider:       gothUser.Provider,
+		ProviderUserID: gothUser.UserID,
+		Email:          gothUser.Email,
+	}, &database.Account{
+		Email:     gothUser.Email,
+		Name:      gothUser.Name,
+		FirstName: firstName,
+		LastName:  gothUser.LastName,
+		AvatarURL: gothUser.AvatarURL,
+	})
 	if err != nil {
 		return err
 	}
 
-	session.Values[UserSessionKey] = string(userData)
+	// Every sign in gets a new session id, so an id planted in the browser
+	// beforehand can't be used to ride on it.
+	if session.ID != "" {
+		if err := store.sessions.DeleteSession(r.Context(), session.ID); err != nil {
+			return err
+		}
+		session.ID = ""
+	}
+
+	session.Values[UserSessionKey] = account.ID
+	session.Values[accessTokenKey] = gothUser.AccessToken
+	session.Values[refreshTokenKey] = gothUser.RefreshToken
 	return session.Save(r, w)
 }
 
-// GetUserFromSession retrieves the user data from the session
+// GetUserFromSession loads the account of the user signed in to the
+// session.
 func GetUserFromSession(r *http.Request) (*User, error) {
 	session, err := store.Get(r, SessionName)
 	if err != nil {
 		return nil, err
 	}
 
-	userDataStr, ok := session.Values[UserSessionKey].(string)
-	if !ok || userDataStr == "" {
+	// Sessions from before accounts existed hold JSON and are ignored.
+	id, ok := session.Values[UserSessionKey].(int64)
+	if !ok {
 		return nil, fmt.Errorf("no user in session")
 	}
 
-	var user User
-	err = json.Unmarshal([]byte(userDataStr), &user)
+	account, err := accounts.GetByID(r.Context(), id)
 	if err != nil {
 		return nil, err
 	}
+	return &User{
+		ID:        account.ID,
+		Email:     account.Email,
+		Name:      account.Name,
+		FirstName: account.FirstName,
+		LastName:  account.LastName,
+		AvatarURL: account.AvatarURL,
+	}, nil
+}
 
-	return &user, nil
+// CurrentSessionID returns the id of the session of r, or "" when it has
+// not been saved yet.
+func CurrentSessionID(r *http.Request) string {
+	session, err := store.Get(r, SessionName)
+	if err != nil {
+		return ""
+	}
+	return session.ID
 }
 
 // GetUserFromContext retrieves the user from the request context (set by RequireAuth middleware)
@@ -158,47 +254,85 @@ func GetUserFromContext(r *http.Request) (*User, error) {
 	return user, nil
 }
 
-// ClearUserSession removes the user from the session
+// ClearUserSession signs the user out, deleting their session.
 func ClearUserSession(w http.ResponseWriter, r *http.Request) error {
 	session, err := store.Get(r, SessionName)
 	if err != nil {
 		return err
 	}
 
-	delete(session.Values, UserSessionKey)
+	session.Options.MaxAge = -1
 	return session.Save(r, w)
 }
 
-func NewAuth(r *chi.Mux) {
-	googleClientId := config.GetConfig("CLIENT_ID")
-	googleClientSecret := config.GetConfig("CLIENT_SECRET")
-	googleClientCallbackURL := config.GetConfig("CLIENT_CALLBACK_URL")
+// identityProviders lists the providers user signed in with, separated by
+// commas.
+func identityProviders(r *http.Request, user *User) string {
+	return listIdentities(r, user, func(id database.Identity) string { return id.Provider })
+}
+
+// identityKeys lists the identities of user as written in
+// ADMIN_IDENTITIES, separated by commas.
+func identityKeys(r *http.Request, user *User) string {
+	return listIdentities(r, user, identityKey)
+}
+
+func listIdentities(r *http.Request, user *User, name func(database.Identity) string) string {
+	ids, err := accounts.ListIdentities(r.Context(), user.ID)
+	if err != nil {
+		return ""
+	}
+	names := make([]string, len(ids))
+	for i, id := range ids {
+		names[i] = name(id)
+	}
+	return strings.Join(names, ", ")
+}
+
+// sessionKeys returns the key pairs of config.SessionKeys. Development
+// falls back to a random pair, signing everyone out on restart, while
+// other environments refuse to start without keys.
+func sessionKeys() [][]byte {
+	keys, err := config.SessionKeys()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(keys) > 0 {
+		return keys
+	}
+	if config.Environment() != config.EnvDevelopment {
+		log.Fatal("SESSION_KEYS is not set; run the genkeys command to make one")
+	}
+	log.Println("auth: SESSION_KEYS is not set, using a random key")
+	return [][]byte{securecookie.GenerateRandomKey(64), securecookie.GenerateRandomKey(32)}
+}
+
+func NewAuth(r *chi.Mux, accountStore database.AccountStore, sessionStore database.SessionStore) {
+	accounts = accountStore
+
+	keys := sessionKeys()
+	secure := config.Environment() != config.EnvDevelopment
 
 	// Configure session store with proper settings
-	store = sessions.NewCookieStore([]byte(key))
+	store = NewDBStore(sessionStore, keys...)
 	store.MaxAge(maxAge)
 	store.Options.Path = "/"
 	store.Options.HttpOnly = true
-	store.Options.Secure = isProd
+	store.Options.Secure = secure
 	store.Options.SameSite = http.SameSiteLaxMode
 
-	gothic.Store = store
+	// Gothic only keeps the OAuth state from /auth/{provider} to the
+	// callback, which clears it. It stays in a short-lived cookie so
+	// anonymous visits don't write sessions to the database.
+	oauthStore := sessions.NewCookieStore(keys...)
+	oauthStore.MaxAge(oauthMaxAge)
+	oauthStore.Options.Path = "/"
+	oauthStore.Options.HttpOnly = true
+	oauthStore.Options.Secure = secure
+	oauthStore.Options.SameSite = http.SameSiteLaxMode
+	gothic.Store = oauthStore
 
-	goth.UseProviders(
-		google.New(googleClientId, googleClientSecret, googleClientCallbackURL, "email", "profile"),
-	)
-
-	m := map[string]string{
-		"google": "Google",
-	}
-
-	var keys []string
-	for k := range m {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
-
-	providerIndex := &ProviderIndex{Providers: keys, ProvidersMap: m}
+	providerIndex := registerProviders()
 
 	// Auth start - wrap with GetProvider middleware
 	r.With(GetProvider).Get("/auth/{provider}", func(res http.ResponseWriter, req *http.Request) {
@@ -226,8 +360,9 @@ func NewAuth(r *chi.Mux) {
 		http.Redirect(res, req, "/dashboard", http.StatusSeeOther)
 	})
 
-	// Logout handler - wrap with GetProvider middleware
-	r.With(GetProvider).Get("/logout/{provider}", func(res http.ResponseWriter, req *http.Request) {
+	// Logout handler. Accounts may have several providers, so the provider
+	// of the old /logout/{provider} URLs is not needed.
+	logout := func(res http.ResponseWriter, req *http.Request) {
 		// Clear our user session
 		err := ClearUserSession(res, req)
 		if err != nil {
@@ -242,7 +377,9 @@ func NewAuth(r *chi.Mux) {
 		}
 
 		http.Redirect(res, req, "/", http.StatusTemporaryRedirect)
-	})
+	}
+	r.Get("/logout", logout)
+	r.With(GetProvider).Get("/logout/{provider}", logout)
 
 	// Public route - login page
 	r.Get("/login", func(res http.ResponseWriter, req *http.Request) {
@@ -263,11 +400,12 @@ func NewAuth(r *chi.Mux) {
 		fmt.Fprintf(res, `
             <h1>Welcome to your Dashboard, %s!</h1>
             <p>Email: %s</p>
-            <p>Provider: %s</p>
+            <p>Providers: %s</p>
             <img src="%s" alt="Avatar" style="width:100px;border-radius:50%%">
             <p><a href="/profile">View Profile</a></p>
-            <p><a href="/logout/%s">Logout</a></p>
-        `, user.Name, user.Email, user.Provider, user.AvatarURL, user.Provider)
+            <p><a href="/account/sessions">Active sessions</a></p>
+            <p><a href="/logout">Logout</a></p>
+        `, user.Name, user.Email, identityProviders(req, user), user.AvatarURL)
 	})
 
 	// Another protected route - profile
@@ -282,14 +420,15 @@ func NewAuth(r *chi.Mux) {
 		res.Header().Set("Content-Type", "text/html")
 		fmt.Fprintf(res, `
             <h1>Profile</h1>
-            <p>ID: %s</p>
+            <p>ID: %d</p>
             <p>Name: %s %s</p>
             <p>Email: %s</p>
-            <p>Provider: %s</p>
+            <p>Providers: %s</p>
+            <p>Identities: %s</p>
             <img src="%s" alt="Avatar" style="width:150px;border-radius:50%%">
             <p><a href="/dashboard">Back to Dashboard</a></p>
-            <p><a href="/logout/%s">Logout</a></p>
-        `, user.ID, user.FirstName, user.LastName, user.Email, user.Provider, user.AvatarURL, user.Provider)
+            <p><a href="/logout">Logout</a></p>
+        `, user.ID, user.FirstName, user.LastName, user.Email, identityProviders(req, user), identityKeys(req, user), user.AvatarURL)
 	})
 
 	// API endpoint example - returns JSON
@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"context"
+	"encoding/hex"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gochi-demo/internal/database"
+	"github.com/gochi-demo/internal/database/dbtest"
+	"github.com/gorilla/securecookie"
+	"github.com/markbates/goth"
+)
+
+func TestSaveUserToSessionRenewsID(t *testing.T) {
+	for _, b := range dbtest.Backends(t) {
+		t.Run(b.Name, func(t *testing.T) {
+			accounts = b.Accounts
+			store = NewDBStore(b.Sessions, securecookie.GenerateRandomKey(64))
+
+			// A session started before signing in, as one planted by an
+			// attacker would be.
+			r := httptest.NewRequest("GET", "/", nil)
+			s, _ := store.New(r, SessionName)
+			w := httptest.NewRecorder()
+			if err := store.Save(r, w, s); err != nil {
+				t.Fatal(err)
+			}
+			planted := s.ID
+
+			w2 := httptest.NewRecorder()
+			err := SaveUserToSession(w2, requestWith(w), goth.User{
+				Provider: "test",
+				UserID:   hex.EncodeToString(securecookie.GenerateRandomKey(8)),
+				Name:     "Test User",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			next, err := store.New(requestWith(w2), SessionName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { b.Sessions.DeleteSession(context.Background(), next.ID) })
+			if next.ID == "" || next.ID == planted {
+				t.Errorf("signed in session id %q; want a new one", next.ID)
+			}
+			if _, ok := next.Values[UserSessionKey].(int64); !ok {
+				t.Errorf("signed in session holds no account: %v", next.Values)
+			}
+			if _, err := b.Sessions.GetSession(context.Background(), planted); err == nil {
+				t.Error("session from before the sign in still exists")
+			}
+		})
+	}
+}
+
+func TestIsAdmin(t *testing.T) {
+	b := dbtest.Backends(t)[0]
+	accounts = b.Accounts
+	ctx := context.Background()
+
+	admin, err := accounts.UpsertLogin(ctx,
+		&database.Identity{Provider: "gitlab", ProviderUserID: "42", Email: "boss@example.com"},
+		&database.Account{Email: "boss@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Claims the email of the admin on another provider.
+	other, err := accounts.UpsertLogin(ctx,
+		&database.Identity{Provider: "oidc", ProviderUserID: "7", Email: "boss@example.com"},
+		&database.Account{Email: "boss@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		admins string
+		user   *User
+		want   bool
+	}{
+		{"listed", "github:1, gitlab:42", &User{ID: admin.ID}, true},
+		{"same email", "gitlab:42", &User{ID: other.ID, Email: "boss@example.com"}, false},
+		{"same user id on another provider", "oidc:42", &User{ID: admin.ID}, false},
+		{"none listed", "", &User{ID: admin.ID}, false},
+		{"no user", "gitlab:42", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ADMIN_IDENTITIES", tt.admins)
+			if got := IsAdmin(ctx, tt.user); got != tt.want {
+				t.Errorf("IsAdmin = %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
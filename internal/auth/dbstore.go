@@ -0,0 +1,163 @@
+package auth
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/gochi-demo/internal/database"
+	"github.com/gorilla/securecookie"
+	"github.com/gorilla/sessions"
+)
+
+// touchInterval is how stale the last seen time of a session may get
+// before a request updates it, sparing a write on every request.
+const touchInterval = time.Minute
+
+// DBStore is a sessions.Store keeping session values in the database. The
+// cookie only holds a random session id, signed with the key pairs, so
+// OAuth tokens and profile data never reach the browser.
+type DBStore struct {
+	Codecs  []securecookie.Codec
+	Options *sessions.Options // default configuration
+
+	sessions database.SessionStore
+	encoder  securecookie.GobEncoder
+}
+
+// NewDBStore returns a store saving sessions in st. The keyPairs are used
+// as with sessions.NewCookieStore; only the hash keys matter, as the id
+// is not secret once signed.
+func NewDBStore(st database.SessionStore, keyPairs ...[]byte) *DBStore {
+	s := &DBStore{
+		Codecs: securecookie.CodecsFromPairs(keyPairs...),
+		Options: &sessions.Options{
+			Path:   "/",
+			MaxAge: 86400 * 30,
+		},
+		sessions: st,
+	}
+	s.MaxAge(s.Options.MaxAge)
+	return s
+}
+
+// Get returns a session for the given name after adding it to the
+// registry, so it is only loaded once per request.
+func (s *DBStore) Get(r *http.Request, name string) (*sessions.Session, error) {
+	return sessions.GetRegistry(r).Get(s, name)
+}
+
+// New loads the session named by the cookie of r, or returns a new one when
+// there is no cookie, the cookie was signed with a key that is gone or
+// the session it names is gone. Deleting a session therefore signs it out
+// on its next request.
+func (s *DBStore) New(r *http.Request, name string) (*sessions.Session, error) {
+	session := sessions.NewSession(s, name)
+	opts := *s.Options
+	session.Options = &opts
+	session.IsNew = true
+
+	c, err := r.Cookie(name)
+	if err != nil {
+		return session, nil
+	}
+	if err := securecookie.DecodeMulti(name, c.Value, &session.ID, s.Codecs...); err != nil {
+		// Signed with a dropped key, or tampered with: start over, as the
+		// cookie stores of gorilla do.
+		session.ID = ""
+		return session, nil
+	}
+
+	row, err := s.sessions.GetSession(r.Context(), session.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Expired or deleted: a new id will be used when saved.
+		session.ID = ""
+		return session, nil
+	}
+	if err != nil {
+		return session, err
+	}
+	if err := s.encoder.Deserialize(row.Data, &session.Values); err != nil {
+		return session, err
+	}
+	session.IsNew = false
+
+	if now := time.Now(); now.Sub(row.LastSeenAt) > touchInterval {
+		if err := s.sessions.TouchSession(r.Context(), session.ID, clientIP(r), r.UserAgent(), now); err != nil {
+			log.Println("sessions:", err)
+		}
+	}
+	return session, nil
+}
+
+// Save writes the session to the database and its id to the cookie. A
+// negative MaxAge deletes the session.
+func (s *DBStore) Save(r *http.Request, w http.ResponseWriter, session *sessions.Session) error {
+	if session.Options.MaxAge < 0 {
+		if session.ID != "" {
+			if err := s.sessions.DeleteSession(r.Context(), session.ID); err != nil {
+				return err
+			}
+		}
+		http.SetCookie(w, sessions.NewCookie(session.Name(), "", session.Options))
+		return nil
+	}
+
+	if session.ID == "" {
+		session.ID = base64.RawURLEncoding.EncodeToString(securecookie.GenerateRandomKey(32))
+	}
+	data, err := s.encoder.Serialize(session.Values)
+	if err != nil {
+		return err
+	}
+	// Sessions ending with the browser still need an end on the server.
+	age := session.Options.MaxAge
+	if age == 0 {
+		age = s.Options.MaxAge
+	}
+	row := &database.Session{
+		ID:        session.ID,
+		Data:      data,
+		IP:        clientIP(r),
+		UserAgent: r.UserAgent(),
+		ExpiresAt: time.Now().Add(time.Duration(age) * time.Second),
+	}
+	if id, ok := session.Values[UserSessionKey].(int64); ok {
+		row.AccountID = &id
+	}
+	err = s.sessions.SaveSession(r.Context(), row)
+	if err != nil {
+		return err
+	}
+
+	encoded, err := securecookie.EncodeMulti(session.Name(), session.ID, s.Codecs...)
+	if err != nil {
+		return err
+	}
+	http.SetCookie(w, sessions.NewCookie(session.Name(), encoded, session.Options))
+	return nil
+}
+
+// clientIP returns the address r comes from, without its port.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// MaxAge sets the maximum age of the sessions of the store and of their
+// cookies.
+func (s *DBStore) MaxAge(age int) {
+	s.Options.MaxAge = age
+	for _, codec := range s.Codecs {
+		if sc, ok := codec.(*securecookie.SecureCookie); ok {
+			sc.MaxAge(age)
+		}
+	}
+}
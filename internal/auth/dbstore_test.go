@@ -0,0 +1,158 @@
+package auth
+
+import (
+	"context"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gochi-demo/internal/database"
+	"github.com/gochi-demo/internal/database/dbtest"
+	"github.com/gorilla/securecookie"
+)
+
+// testAccount signs a new account in with a made up identity.
+func testAccount(t *testing.T, accounts database.AccountStore) int64 {
+	t.Helper()
+	id := hex.EncodeToString(securecookie.GenerateRandomKey(8))
+	account, err := accounts.UpsertLogin(context.Background(),
+		&database.Identity{Provider: "test", ProviderUserID: id},
+		&database.Account{Name: "Test " + id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return account.ID
+}
+
+// requestWith returns a request carrying the cookies set by w.
+func requestWith(w *httptest.ResponseRecorder) *http.Request {
+	r := httptest.NewRequest("GET", "/", nil)
+	for _, c := range w.Result().Cookies() {
+		r.AddCookie(c)
+	}
+	return r
+}
+
+func TestDBStoreRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		// revoke ends the session between the two requests.
+		revoke func(st database.SessionStore, id string, account int64) error
+		kept   bool
+	}{
+		{"kept", nil, true},
+		{"revoked", func(st database.SessionStore, id string, _ int64) error {
+			return st.DeleteSession(context.Background(), id)
+		}, false},
+		{"account signed out", func(st database.SessionStore, _ string, account int64) error {
+			_, err := st.DeleteAccountSessions(context.Background(), account)
+			return err
+		}, false},
+	}
+
+	for _, b := range dbtest.Backends(t) {
+		for _, tt := range tests {
+			t.Run(b.Name+"/"+tt.name, func(t *testing.T) {
+				store := NewDBStore(b.Sessions, securecookie.GenerateRandomKey(64), securecookie.GenerateRandomKey(32))
+				account := testAccount(t, b.Accounts)
+
+				r := httptest.NewRequest("GET", "/", nil)
+				s, err := store.New(r, SessionName)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !s.IsNew {
+					t.Fatal("session of a request without cookie is not new")
+				}
+				s.Values[UserSessionKey] = account
+				w := httptest.NewRecorder()
+				if err := store.Save(r, w, s); err != nil {
+					t.Fatal(err)
+				}
+				id := s.ID
+				t.Cleanup(func() { b.Sessions.DeleteSession(context.Background(), id) })
+
+				if tt.revoke != nil {
+					if err := tt.revoke(b.Sessions, id, account); err != nil {
+						t.Fatal(err)
+					}
+				}
+
+				next, err := store.New(requestWith(w), SessionName)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !tt.kept {
+					if !next.IsNew || next.ID != "" || len(next.Values) != 0 {
+						t.Errorf("revoked session loaded as %q with %v", next.ID, next.Values)
+					}
+					return
+				}
+				if next.IsNew || next.ID != id {
+					t.Fatalf("session loaded as %q, new %v; want %q", next.ID, next.IsNew, id)
+				}
+				if next.Values[UserSessionKey] != account {
+					t.Errorf("Values = %v; want account %d", next.Values, account)
+				}
+			})
+		}
+	}
+}
+
+func TestDBStoreSaveDeletes(t *testing.T) {
+	for _, b := range dbtest.Backends(t) {
+		t.Run(b.Name, func(t *testing.T) {
+			store := NewDBStore(b.Sessions, securecookie.GenerateRandomKey(64))
+
+			r := httptest.NewRequest("GET", "/", nil)
+			s, _ := store.New(r, SessionName)
+			w := httptest.NewRecorder()
+			if err := store.Save(r, w, s); err != nil {
+				t.Fatal(err)
+			}
+
+			r = requestWith(w)
+			s, err := store.New(r, SessionName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			id := s.ID
+			s.Options.MaxAge = -1
+			if err := store.Save(r, httptest.NewRecorder(), s); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := b.Sessions.GetSession(context.Background(), id); err == nil {
+				t.Error("session still stored after saving it with a negative MaxAge")
+			}
+		})
+	}
+}
+
+func TestDBStoreUnknownKey(t *testing.T) {
+	for _, b := range dbtest.Backends(t) {
+		t.Run(b.Name, func(t *testing.T) {
+			old := NewDBStore(b.Sessions, securecookie.GenerateRandomKey(64), securecookie.GenerateRandomKey(32))
+			store := NewDBStore(b.Sessions, securecookie.GenerateRandomKey(64), securecookie.GenerateRandomKey(32))
+
+			r := httptest.NewRequest("GET", "/", nil)
+			s, _ := old.New(r, SessionName)
+			s.Values[UserSessionKey] = testAccount(t, b.Accounts)
+			w := httptest.NewRecorder()
+			if err := old.Save(r, w, s); err != nil {
+				t.Fatal(err)
+			}
+			id := s.ID
+			t.Cleanup(func() { b.Sessions.DeleteSession(context.Background(), id) })
+
+			next, err := store.New(requestWith(w), SessionName)
+			if err != nil {
+				t.Fatalf("New with a cookie of another key: %v", err)
+			}
+			if !next.IsNew || next.ID != "" || len(next.Values) != 0 {
+				t.Errorf("session loaded as %q, new %v, with %v; want a new empty one", next.ID, next.IsNew, next.Values)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/gochi-demo/internal/config"
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/github"
+	"github.com/markbates/goth/providers/gitlab"
+	"github.com/markbates/goth/providers/google"
+	"github.com/markbates/goth/providers/microsoftonline"
+	"github.com/markbates/goth/providers/openidConnect"
+	"golang.org/x/oauth2"
+)
+
+// providerKind describes a supported provider: its login page label, its
+// default scopes and how to build it.
+type providerKind struct {
+	label  string
+	scopes []string
+	build  func(p config.AuthProvider, scopes []string) (goth.Provider, error)
+}
+
+// providerKinds are the providers AUTH_PROVIDERS may list.
+var providerKinds = map[string]providerKind{
+	"google": {
+		label:  "Google",
+		scopes: []string{"email", "profile"},
+		build: func(p config.AuthProvider, scopes []string) (goth.Provider, error) {
+			return google.New(p.ClientID, p.ClientSecret, p.CallbackURL, scopes...), nil
+		},
+	},
+	"github": {
+		label:  "GitHub",
+		scopes: []string{"read:user", "user:email"},
+		build: func(p config.AuthProvider, scopes []string) (goth.Provider, error) {
+			if p.URL == "" {
+				return github.New(p.ClientID, p.ClientSecret, p.CallbackURL, scopes...), nil
+			}
+			// GitHub Enterprise Server.
+			return github.NewCustomisedURL(p.ClientID, p.ClientSecret, p.CallbackURL,
+				p.URL+"/login/oauth/authorize", p.URL+"/login/oauth/access_token",
+				p.URL+"/api/v3/user", p.URL+"/api/v3/user/emails", scopes...), nil
+		},
+	},
+	"gitlab": {
+		label:  "GitLab",
+		scopes: []string{"read_user"},
+		build: func(p config.AuthProvider, scopes []string) (goth.Provider, error) {
+			if p.URL == "" {
+				return gitlab.New(p.ClientID, p.ClientSecret, p.CallbackURL, scopes...), nil
+			}
+			return gitlab.NewCustomisedURL(p.ClientID, p.ClientSecret, p.CallbackURL,
+				p.URL+"/oauth/authorize", p.URL+"/oauth/token", p.URL+"/api/v4/user", scopes...), nil
+		},
+	},
+	"microsoft": {
+		label: "Microsoft",
+		// The provider always asks for openid, offline_access and user.read.
+		build: func(p config.AuthProvider, scopes []string) (goth.Provider, error) {
+			provider := microsoftonline.New(p.ClientID, p.ClientSecret, p.CallbackURL, scopes...)
+			provider.SetName(p.Name)
+			return provider, nil
+		},
+	},
+	"oidc": {
+		label:  "OpenID Connect",
+		scopes: []string{"openid", "profile", "email"},
+		build: func(p config.AuthProvider, scopes []string) (goth.Provider, error) {
+			if p.URL == "" {
+				return nil, errors.New("OIDC_URL is not set")
+			}
+			// Endpoints are read from the discovery document of the issuer,
+			// again on sign in while the issuer can't be reached.
+			provider := &lazyProvider{name: p.Name, build: func() (goth.Provider, error) {
+				return openidConnect.New(p.ClientID, p.ClientSecret, p.CallbackURL, discoveryURL(p.URL), scopes...)
+			}}
+			if _, err := provider.get(); err != nil {
+				log.Printf("auth: provider %s unavailable, retrying on sign in: %v", p.Name, err)
+			}
+			return provider, nil
+		},
+	},
+}
+
+// lazyProvider is a provider built on first use, and built again on later
+// uses until that succeeds, so a provider whose setup needs the network is
+// only down as long as the network is.
+type lazyProvider struct {
+	name  string
+	build func() (goth.Provider, error)
+
+	mu       sync.Mutex
+	provider goth.Provider
+}
+
+func (p *lazyProvider) get() (goth.Provider, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.provider == nil {
+		provider, err := p.build()
+		if err != nil {
+			return nil, err
+		}
+		provider.SetName(p.name)
+		p.provider = provider
+	}
+	return p.provider, nil
+}
+
+func (p *lazyProvider) Name() string { return p.name }
+
+func (p *lazyProvider) SetName(name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.name = name
+	if p.provider != nil {
+		p.provider.SetName(name)
+	}
+}
+
+func (p *lazyProvider) BeginAuth(state string) (goth.Session, error) {
+	provider, err := p.get()
+	if err != nil {
+		return nil, err
+	}
+	return provider.BeginAuth(state)
+}
+
+func (p *lazyProvider) UnmarshalSession(data string) (goth.Session, error) {
+	provider, err := p.get()
+	if err != nil {
+		return nil, err
+	}
+	return provider.UnmarshalSession(data)
+}
+
+func (p *lazyProvider) FetchUser(session goth.Session) (goth.User, error) {
+	provider, err := p.get()
+	if err != nil {
+		return goth.User{}, err
+	}
+	return provider.FetchUser(session)
+}
+
+func (p *lazyProvider) Debug(bool) {}
+
+func (p *lazyProvider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	provider, err := p.get()
+	if err != nil {
+		return nil, err
+	}
+	return provider.RefreshToken(refreshToken)
+}
+
+func (p *lazyProvider) RefreshTokenAvailable() bool {
+	provider, err := p.get()
+	return err == nil && provider.RefreshTokenAvailable()
+}
+
+// discoveryURL returns the OpenID discovery document URL of an issuer,
+// unless url already is one.
+func discoveryURL(url string) string {
+	const wellKnown = "/.well-known/openid-configuration"
+	if strings.HasSuffix(url, wellKnown) {
+		return url
+	}
+	return url + wellKnown
+}
+
+// registerProviders hands the providers of config.GetAuthProviders to goth
+// and returns the index of those registered, for the login page.
+// Misconfigured providers are logged and left out, so an unreachable OIDC
+// server doesn't keep the site from starting.
+func registerProviders() *ProviderIndex {
+	index := &ProviderIndex{ProvidersMap: map[string]string{}}
+	var providers []goth.Provider
+
+	for _, p := range config.GetAuthProviders() {
+		provider, err := buildProvider(p)
+		if err != nil {
+			log.Printf("auth: provider %s disabled: %v", p.Name, err)
+			continue
+		}
+		providers = append(providers, provider)
+
+		label := p.Label
+		if label == "" {
+			label = providerKinds[p.Name].label
+		}
+		index.Providers = append(index.Providers, p.Name)
+		index.ProvidersMap[p.Name] = label
+	}
+
+	goth.ClearProviders()
+	goth.UseProviders(providers...)
+	return index
+}
+
+func buildProvider(p config.AuthProvider) (goth.Provider, error) {
+	kind, ok := providerKinds[p.Name]
+	if !ok {
+		return nil, errors.New("unknown provider")
+	}
+	if p.ClientID == "" {
+		return nil, fmt.Errorf("%s_CLIENT_ID is not set", strings.ToUpper(p.Name))
+	}
+
+	scopes := p.Scopes
+	if len(scopes) == 0 {
+		scopes = kind.scopes
+	}
+	return kind.build(p, scopes)
+}
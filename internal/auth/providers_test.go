@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gochi-demo/internal/config"
+)
+
+func TestOIDCDiscoveryRetried(t *testing.T) {
+	var up atomic.Bool
+	var issuer *httptest.Server
+	issuer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !up.Load() {
+			http.Error(w, "down", http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 issuer.URL,
+			"authorization_endpoint": issuer.URL + "/authorize",
+			"token_endpoint":         issuer.URL + "/token",
+		})
+	}))
+	defer issuer.Close()
+
+	provider, err := buildProvider(config.AuthProvider{
+		Name:        "oidc",
+		ClientID:    "client",
+		CallbackURL: "http://localhost/auth/oidc/callback",
+		URL:         issuer.URL,
+	})
+	if err != nil {
+		t.Fatalf("provider disabled while its issuer is down: %v", err)
+	}
+	if provider.Name() != "oidc" {
+		t.Errorf("Name = %q; want oidc", provider.Name())
+	}
+
+	if _, err := provider.BeginAuth("state"); err == nil {
+		t.Fatal("BeginAuth succeeded while the issuer is down")
+	}
+
+	up.Store(true)
+	session, err := provider.BeginAuth("state")
+	if err != nil {
+		t.Fatalf("BeginAuth once the issuer is back: %v", err)
+	}
+	url, err := session.GetAuthURL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(url, issuer.URL+"/authorize") {
+		t.Errorf("auth URL %q; want one of the issuer", url)
+	}
+}
@@ -0,0 +1,63 @@
+package config
+
+import "strings"
+
+// AuthProvider holds the settings of an OAuth provider users can sign in
+// with.
+type AuthProvider struct {
+	// Name is the name of the provider in AUTH_PROVIDERS and in the
+	// /auth/{provider} URLs.
+	Name string
+	// Label is shown on the login page.
+	Label        string
+	ClientID     string
+	ClientSecret string
+	CallbackURL  string
+	// Scopes replace the default scopes of the provider when set.
+	Scopes []string
+	// URL is the base URL of a self-hosted GitLab, or the issuer or
+	// discovery document URL of an OpenID Connect provider.
+	URL string
+}
+
+// GetAuthProviders reads the providers listed in AUTH_PROVIDERS, separated
+// by commas, in order. Each one is configured by the settings prefixed
+// with its upper cased name: GITHUB_CLIENT_ID, GITHUB_CLIENT_SECRET,
+// GITHUB_SCOPES, GITHUB_CALLBACK_URL, GITHUB_LABEL and GITHUB_URL for
+// github. Scopes are separated by commas or spaces and callback URLs
+// default to SITE_URL/auth/{provider}/callback.
+//
+// Google is the only provider when AUTH_PROVIDERS is unset, and falls back
+// to the CLIENT_ID, CLIENT_SECRET and CLIENT_CALLBACK_URL settings.
+func GetAuthProviders() []AuthProvider {
+	var providers []AuthProvider
+	for _, name := range strings.Split(GetConfigWithDefault("AUTH_PROVIDERS", "google"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		prefix := strings.ToUpper(name) + "_"
+
+		p := AuthProvider{
+			Name:         name,
+			Label:        GetConfig(prefix + "LABEL"),
+			ClientID:     GetConfig(prefix + "CLIENT_ID"),
+			ClientSecret: GetConfig(prefix + "CLIENT_SECRET"),
+			CallbackURL:  GetConfig(prefix + "CALLBACK_URL"),
+			Scopes: strings.FieldsFunc(GetConfig(prefix+"SCOPES"), func(r rune) bool {
+				return r == ',' || r == ' '
+			}),
+			URL: strings.TrimRight(GetConfig(prefix+"URL"), "/"),
+		}
+		if name == "google" {
+			p.ClientID = GetConfigWithDefault(prefix+"CLIENT_ID", GetConfig("CLIENT_ID"))
+			p.ClientSecret = GetConfigWithDefault(prefix+"CLIENT_SECRET", GetConfig("CLIENT_SECRET"))
+			p.CallbackURL = GetConfigWithDefault(prefix+"CALLBACK_URL", GetConfig("CLIENT_CALLBACK_URL"))
+		}
+		if p.CallbackURL == "" {
+			p.CallbackURL = GetSite().URL + "/auth/" + name + "/callback"
+		}
+		providers = append(providers, p)
+	}
+	return providers
+}
@@ -5,7 +5,9 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strconv"
 	"sync"
+	"testing"
 
 	"github.com/joho/godotenv"
 )
@@ -17,9 +19,13 @@ var (
 
 // Load reads the .env file once and loads variables into the environment.
 // It's called automatically by GetConfig, but you can call it explicitly
-// at startup if you want to handle errors early.
+// at startup if you want to handle errors early. Tests read the
+// environment alone and set what they need with t.Setenv.
 func Load() error {
 	once.Do(func() {
+		if testing.Testing() {
+			return
+		}
 		exePath, _ := os.Executable()
 		fmt.Println("exePath:", exePath)
 
@@ -63,3 +69,14 @@ func GetConfigWithDefault(key, defaultValue string) string {
 	}
 	return val
 }
+
+// GetIntConfigWithDefault retrieves an integer config value or returns a
+// default when the key is missing or not a valid number.
+func GetIntConfigWithDefault(key string, defaultValue int) int {
+	Load()
+	val, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return val
+}
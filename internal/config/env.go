@@ -0,0 +1,16 @@
+package config
+
+import "strings"
+
+// Environment profiles, selected with APP_ENV.
+const (
+	EnvDevelopment = "development"
+	EnvStaging     = "staging"
+	EnvProduction  = "production"
+)
+
+// Environment returns the APP_ENV profile the server runs with,
+// EnvDevelopment when unset.
+func Environment() string {
+	return strings.ToLower(GetConfigWithDefault("APP_ENV", EnvDevelopment))
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// Sizes of the keys written by GenerateSessionKeyPair: a 64 byte HMAC key
+// and a 32 byte AES-256 key.
+const (
+	sessionHashKeySize  = 64
+	sessionBlockKeySize = 32
+)
+
+// SessionKeys decodes SESSION_KEYS into the key pairs taken by
+// securecookie.CodecsFromPairs. Pairs are separated by commas, newest
+// first, each written as base64 "hash:block". The newest pair signs new
+// cookies and older ones are still accepted, so keys can be rotated by
+// adding a pair in front and dropping the last one once its cookies
+// expired. The block key, encrypting cookies, may be left out.
+//
+// SessionKeys returns no pairs when SESSION_KEYS is unset.
+func SessionKeys() ([][]byte, error) {
+	var keys [][]byte
+	for i, pair := range strings.Split(GetConfig("SESSION_KEYS"), ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		hashPart, blockPart, _ := strings.Cut(pair, ":")
+		hashKey, err := base64.StdEncoding.DecodeString(hashPart)
+		if err != nil {
+			return nil, fmt.Errorf("SESSION_KEYS pair %d: hash key: %w", i+1, err)
+		}
+		if len(hashKey) < 32 {
+			return nil, fmt.Errorf("SESSION_KEYS pair %d: hash key must be at least 32 bytes", i+1)
+		}
+
+		var blockKey []byte
+		if blockPart != "" {
+			if blockKey, err = base64.StdEncoding.DecodeString(blockPart); err != nil {
+				return nil, fmt.Errorf("SESSION_KEYS pair %d: block key: %w", i+1, err)
+			}
+			if n := len(blockKey); n != 16 && n != 24 && n != 32 {
+				return nil, fmt.Errorf("SESSION_KEYS pair %d: block key must be 16, 24 or 32 bytes", i+1)
+			}
+		}
+		keys = append(keys, hashKey, blockKey)
+	}
+	return keys, nil
+}
+
+// GenerateSessionKeyPair returns a new random key pair in the format of
+// SESSION_KEYS.
+func GenerateSessionKeyPair() (string, error) {
+	hashKey := make([]byte, sessionHashKeySize)
+	blockKey := make([]byte, sessionBlockKeySize)
+	if _, err := rand.Read(hashKey); err != nil {
+		return "", err
+	}
+	if _, err := rand.Read(blockKey); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(hashKey) + ":" + base64.StdEncoding.EncodeToString(blockKey), nil
+}
@@ -0,0 +1,23 @@
+package config
+
+import "strings"
+
+// Site holds the public facing settings of the blog.
+type Site struct {
+	// URL is the absolute base URL the site is reachable at, without a
+	// trailing slash. Feeds and sitemaps need absolute links.
+	URL         string
+	Title       string
+	Description string
+	Author      string
+}
+
+// GetSite reads the SITE_* settings, falling back to the demo defaults.
+func GetSite() Site {
+	return Site{
+		URL:         strings.TrimRight(GetConfigWithDefault("SITE_URL", "http://localhost:10000"), "/"),
+		Title:       GetConfigWithDefault("SITE_TITLE", "AstroPaper"),
+		Description: GetConfigWithDefault("SITE_DESCRIPTION", "A minimal, responsive and accessible theme for Astro."),
+		Author:      GetConfigWithDefault("SITE_AUTHOR", "AstroPaper"),
+	}
+}
@@ -0,0 +1,47 @@
+package database
+
+import (
+	"context"
+	"time"
+)
+
+// Account is a local user. People sign in through OAuth providers, each
+// sign in method being an Identity linked to the account.
+type Account struct {
+	ID          int64     `db:"id" json:"id"`
+	Email       string    `db:"email" json:"email"`
+	Name        string    `db:"name" json:"name"`
+	FirstName   string    `db:"first_name" json:"first_name"`
+	LastName    string    `db:"last_name" json:"last_name"`
+	AvatarURL   string    `db:"avatar_url" json:"avatar_url"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	LastLoginAt time.Time `db:"last_login_at" json:"last_login_at"`
+}
+
+// Identity links the user id given by an OAuth provider to an account.
+type Identity struct {
+	Provider       string    `db:"provider" json:"provider"`
+	ProviderUserID string    `db:"provider_user_id" json:"provider_user_id"`
+	AccountID      int64     `db:"account_id" json:"account_id"`
+	Email          string    `db:"email" json:"email"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+	LastLoginAt    time.Time `db:"last_login_at" json:"last_login_at"`
+}
+
+type AccountStore interface {
+	GetByID(ctx context.Context, id int64) (*Account, error)
+	// List returns every account, most recently signed in first.
+	List(ctx context.Context) ([]Account, error)
+	// UpsertLogin records a sign in with the identity id. The account it is
+	// linked to gets the profile fields of profile, or is created from them
+	// the first time the identity is seen. Identities are never linked by
+	// email, as providers don't all verify them.
+	UpsertLogin(ctx context.Context, id *Identity, profile *Account) (*Account, error)
+	// ListIdentities returns the identities of an account, most recently
+	// used first.
+	ListIdentities(ctx context.Context, accountID int64) ([]Identity, error)
+}
+
+const accountColumns = "id, email, name, first_name, last_name, avatar_url, created_at, last_login_at"
+
+const identityColumns = "provider, provider_user_id, account_id, email, created_at, last_login_at"
@@ -0,0 +1,33 @@
+package database
+
+import (
+	"fmt"
+	"time"
+)
+
+// MonthCount is the number of published posts of a month.
+type MonthCount struct {
+	Year  int `db:"year" json:"year"`
+	Month int `db:"month" json:"month"`
+	Count int `db:"count" json:"count"`
+}
+
+// Start returns the first instant of the month, in UTC.
+func (m *MonthCount) Start() time.Time {
+	return time.Date(m.Year, time.Month(m.Month), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// URL returns the public path of the month's archive page.
+func (m *MonthCount) URL() string {
+	return MonthURL(m.Year, time.Month(m.Month))
+}
+
+// YearURL returns the public path of the archive page of a year.
+func YearURL(year int) string {
+	return fmt.Sprintf("/%04d", year)
+}
+
+// MonthURL returns the public path of the archive page of a month.
+func MonthURL(year int, month time.Month) string {
+	return fmt.Sprintf("/%04d/%02d", year, int(month))
+}
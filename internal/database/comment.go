@@ -0,0 +1,55 @@
+package database
+
+import (
+	"context"
+	"html/template"
+	"time"
+)
+
+// Comment statuses. New comments wait in the moderation queue as pending
+// and only approved ones are shown.
+const (
+	CommentPending  = "pending"
+	CommentApproved = "approved"
+	CommentRejected = "rejected"
+	CommentSpam     = "spam"
+)
+
+// CommentStatuses lists the valid comment statuses.
+var CommentStatuses = []string{CommentPending, CommentApproved, CommentRejected, CommentSpam}
+
+type Comment struct {
+	ID       int64  `db:"id" json:"id"`
+	PostID   int64  `db:"post_id" json:"post_id"`
+	ParentID *int64 `db:"parent_id" json:"parent_id,omitempty"`
+	// AuthorID is the account id of the author.
+	AuthorID     string    `db:"author_id" json:"-"`
+	AuthorName   string    `db:"author_name" json:"author_name"`
+	AuthorAvatar string    `db:"author_avatar" json:"author_avatar"`
+	Body         string    `db:"body" json:"body"`
+	Status       string    `db:"status" json:"status"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+
+	// PostSlug and PostTitle are only loaded by ListByStatus.
+	PostSlug  string `db:"post_slug" json:"-"`
+	PostTitle string `db:"post_title" json:"-"`
+
+	// HTML is the rendered Body and Replies the approved answers to the
+	// comment, both filled in by the app.
+	HTML    template.HTML `db:"-" json:"-"`
+	Replies []*Comment    `db:"-" json:"-"`
+}
+
+type CommentStore interface {
+	Create(ctx context.Context, c *Comment) error
+	GetByID(ctx context.Context, id int64) (*Comment, error)
+	// ListApproved returns the approved comments of a post, oldest first.
+	ListApproved(ctx context.Context, postID int64) ([]Comment, error)
+	// ListByStatus returns up to limit comments of any post with the given
+	// status, newest first, skipping the first offset.
+	ListByStatus(ctx context.Context, status string, limit, offset int) ([]Comment, error)
+	CountByStatus(ctx context.Context, status string) (int, error)
+	SetStatus(ctx context.Context, id int64, status string) error
+}
+
+const commentColumns = "c.id, c.post_id, c.parent_id, c.author_id, c.author_name, c.author_avatar, c.body, c.status, c.created_at"
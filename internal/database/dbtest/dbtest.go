@@ -0,0 +1,76 @@
+// Package dbtest opens the databases that store tests run against.
+package dbtest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gochi-demo/internal/database"
+	"github.com/jmoiron/sqlx"
+)
+
+// Backend is a database the tests run against, with its stores.
+type Backend struct {
+	Name     string
+	DB       *sqlx.DB
+	Sessions database.SessionStore
+	Accounts database.AccountStore
+	Posts    database.PostStore
+	Search   database.SearchStore
+	Related  database.RelatedStore
+	Series   database.SeriesStore
+}
+
+// Backends returns a fresh SQLite database and, when TEST_PG_DSN is set,
+// the Postgres database it names, both with the schema of the app.
+func Backends(t testing.TB) []Backend {
+	t.Helper()
+
+	db, err := sqlx.Open("sqlite", "file:"+filepath.Join(t.TempDir(), "test.db")+"?_time_format=sqlite&_pragma=foreign_keys(1)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	database.InitSqliteDB(db)
+	backends := []Backend{{
+		Name:     "sqlite",
+		DB:       db,
+		Sessions: database.NewSQLiteSessionStore(db),
+		Accounts: database.NewSQLiteAccountStore(db),
+		Posts:    database.NewSQLitePostStore(db),
+		Search:   database.NewSQLiteSearchStore(db),
+		Related:  database.NewSQLiteRelatedStore(db),
+		Series:   database.NewSQLiteSeriesStore(db),
+	}}
+
+	if dsn := os.Getenv("TEST_PG_DSN"); dsn != "" {
+		pgdb, err := database.NewPostgres(dsn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { pgdb.Close() })
+		database.InitPgDB(pgdb)
+		backends = append(backends, Backend{
+			Name:     "postgres",
+			DB:       pgdb,
+			Sessions: database.NewPgSessionStore(pgdb),
+			Accounts: database.NewPgAccountStore(pgdb),
+			Posts:    database.NewPgPostStore(pgdb),
+			Search:   database.NewPgSearchStore(pgdb),
+			Related:  database.NewPgRelatedStore(pgdb),
+			Series:   database.NewPgSeriesStore(pgdb),
+		})
+	}
+	return backends
+}
+
+// ID returns a random id starting with the name of the test, as Postgres
+// databases outlive tests.
+func ID(t testing.TB) string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return t.Name() + "-" + hex.EncodeToString(b)
+}
@@ -0,0 +1,103 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Media is an uploaded file. Images carry their dimensions and the resized
+// copies made of them.
+type Media struct {
+	ID          int64          `db:"id" json:"id"`
+	Key         string         `db:"key" json:"key"`
+	ContentType string         `db:"content_type" json:"content_type"`
+	Size        int64          `db:"size" json:"size"`
+	Width       int            `db:"width" json:"width"`
+	Height      int            `db:"height" json:"height"`
+	UploadedBy  string         `db:"uploaded_by" json:"uploaded_by"`
+	CreatedAt   time.Time      `db:"created_at" json:"created_at"`
+	Variants    []MediaVariant `db:"-" json:"variants"`
+}
+
+// MediaVariant is a resized copy of an image, stored under its own key.
+type MediaVariant struct {
+	MediaID int64  `db:"media_id" json:"-"`
+	Key     string `db:"key" json:"key"`
+	Width   int    `db:"width" json:"width"`
+	Height  int    `db:"height" json:"height"`
+}
+
+// MediaURL returns the public path of the blob stored under key.
+func MediaURL(key string) string {
+	return "/media/" + key
+}
+
+func (m *Media) URL() string {
+	return MediaURL(m.Key)
+}
+
+func (v *MediaVariant) URL() string {
+	return MediaURL(v.Key)
+}
+
+type MediaStore interface {
+	// Create inserts m and its variants.
+	Create(ctx context.Context, m *Media) error
+	// ListByKeys returns the media stored under keys, with their variants
+	// narrowest first. Unknown keys are skipped.
+	ListByKeys(ctx context.Context, keys []string) ([]Media, error)
+}
+
+const mediaColumns = "id, key, content_type, size, width, height, uploaded_by, created_at"
+
+// listMediaByKeys implements ListByKeys for both databases.
+func listMediaByKeys(ctx context.Context, db *sqlx.DB, keys []string) ([]Media, error) {
+	var media []Media
+	for len(keys) > 0 {
+		batch := keys[:min(len(keys), tagBatchSize)]
+		keys = keys[len(batch):]
+
+		query, args, err := sqlx.In("SELECT "+mediaColumns+" FROM media WHERE key IN (?)", batch)
+		if err != nil {
+			return nil, err
+		}
+		var rows []Media
+		if err := db.SelectContext(ctx, &rows, db.Rebind(query), args...); err != nil {
+			return nil, err
+		}
+		media = append(media, rows...)
+	}
+	if len(media) == 0 {
+		return media, nil
+	}
+
+	byID := make(map[int64]*Media, len(media))
+	ids := make([]int64, len(media))
+	for i := range media {
+		ids[i] = media[i].ID
+		byID[media[i].ID] = &media[i]
+	}
+
+	for len(ids) > 0 {
+		batch := ids[:min(len(ids), tagBatchSize)]
+		ids = ids[len(batch):]
+
+		query, args, err := sqlx.In(`
+			SELECT media_id, key, width, height FROM media_variants
+			WHERE media_id IN (?) ORDER BY width`, batch)
+		if err != nil {
+			return nil, err
+		}
+		var rows []MediaVariant
+		if err := db.SelectContext(ctx, &rows, db.Rebind(query), args...); err != nil {
+			return nil, err
+		}
+		for _, v := range rows {
+			m := byID[v.MediaID]
+			m.Variants = append(m.Variants, v)
+		}
+	}
+	return media, nil
+}
@@ -0,0 +1,95 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type PgAccountStore struct {
+	db *sqlx.DB
+}
+
+func NewPgAccountStore(db *sqlx.DB) *PgAccountStore {
+	return &PgAccountStore{db: db}
+}
+
+func (s *PgAccountStore) GetByID(ctx context.Context, id int64) (*Account, error) {
+	var a Account
+	err := s.db.GetContext(ctx, &a, "SELECT "+accountColumns+" FROM accounts WHERE id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (s *PgAccountStore) List(ctx context.Context) ([]Account, error) {
+	var accounts []Account
+	err := s.db.SelectContext(ctx, &accounts, "SELECT "+accountColumns+" FROM accounts ORDER BY last_login_at DESC")
+	return accounts, err
+}
+
+func (s *PgAccountStore) UpsertLogin(ctx context.Context, id *Identity, profile *Account) (*Account, error) {
+	now := time.Now().UTC()
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var accountID int64
+	err = tx.GetContext(ctx, &accountID,
+		"SELECT account_id FROM identities WHERE provider = $1 AND provider_user_id = $2 FOR UPDATE",
+		id.Provider, id.ProviderUserID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		err = tx.GetContext(ctx, &accountID, `
+			INSERT INTO accounts(email, name, first_name, last_name, avatar_url, created_at, last_login_at)
+			VALUES($1, $2, $3, $4, $5, $6, $6) RETURNING id`,
+			profile.Email, profile.Name, profile.FirstName, profile.LastName, profile.AvatarURL, now)
+		if err != nil {
+			return nil, err
+		}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO identities(provider, provider_user_id, account_id, email, created_at, last_login_at)
+			VALUES($1, $2, $3, $4, $5, $5)`,
+			id.Provider, id.ProviderUserID, accountID, id.Email, now)
+		if err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	default:
+		_, err = tx.ExecContext(ctx, `
+			UPDATE accounts SET email = $1, name = $2, first_name = $3, last_name = $4, avatar_url = $5, last_login_at = $6
+			WHERE id = $7`,
+			profile.Email, profile.Name, profile.FirstName, profile.LastName, profile.AvatarURL, now, accountID)
+		if err != nil {
+			return nil, err
+		}
+		_, err = tx.ExecContext(ctx,
+			"UPDATE identities SET email = $1, last_login_at = $2 WHERE provider = $3 AND provider_user_id = $4",
+			id.Email, now, id.Provider, id.ProviderUserID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var a Account
+	err = tx.GetContext(ctx, &a, "SELECT "+accountColumns+" FROM accounts WHERE id = $1", accountID)
+	if err != nil {
+		return nil, err
+	}
+	return &a, tx.Commit()
+}
+
+func (s *PgAccountStore) ListIdentities(ctx context.Context, accountID int64) ([]Identity, error) {
+	var ids []Identity
+	err := s.db.SelectContext(ctx, &ids,
+		"SELECT "+identityColumns+" FROM identities WHERE account_id = $1 ORDER BY last_login_at DESC", accountID)
+	return ids, err
+}
@@ -0,0 +1,75 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type PgCommentStore struct {
+	db *sqlx.DB
+}
+
+func NewPgCommentStore(db *sqlx.DB) *PgCommentStore {
+	return &PgCommentStore{db: db}
+}
+
+func (s *PgCommentStore) Create(ctx context.Context, c *Comment) error {
+	c.CreatedAt = time.Now().UTC()
+	if c.Status == "" {
+		c.Status = CommentPending
+	}
+
+	return s.db.QueryRowxContext(ctx, `
+		INSERT INTO comments(post_id, parent_id, author_id, author_name, author_avatar, body, status, created_at)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`,
+		c.PostID, c.ParentID, c.AuthorID, c.AuthorName, c.AuthorAvatar, c.Body, c.Status, c.CreatedAt).Scan(&c.ID)
+}
+
+func (s *PgCommentStore) GetByID(ctx context.Context, id int64) (*Comment, error) {
+	var c Comment
+	err := s.db.GetContext(ctx, &c, "SELECT "+commentColumns+" FROM comments c WHERE c.id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (s *PgCommentStore) ListApproved(ctx context.Context, postID int64) ([]Comment, error) {
+	var comments []Comment
+	err := s.db.SelectContext(ctx, &comments,
+		"SELECT "+commentColumns+" FROM comments c WHERE c.post_id = $1 AND c.status = 'approved' ORDER BY c.created_at, c.id",
+		postID)
+	return comments, err
+}
+
+func (s *PgCommentStore) ListByStatus(ctx context.Context, status string, limit, offset int) ([]Comment, error) {
+	var comments []Comment
+	err := s.db.SelectContext(ctx, &comments, `
+		SELECT `+commentColumns+`, p.slug AS post_slug, p.title AS post_title
+		FROM comments c JOIN posts p ON p.id = c.post_id
+		WHERE c.status = $1
+		ORDER BY c.created_at DESC, c.id DESC LIMIT $2 OFFSET $3`, status, limit, offset)
+	return comments, err
+}
+
+func (s *PgCommentStore) CountByStatus(ctx context.Context, status string) (int, error) {
+	var n int
+	err := s.db.GetContext(ctx, &n, "SELECT count(*) FROM comments WHERE status = $1", status)
+	return n, err
+}
+
+func (s *PgCommentStore) SetStatus(ctx context.Context, id int64, status string) error {
+	res, err := s.db.ExecContext(ctx, "UPDATE comments SET status = $1 WHERE id = $2", status, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
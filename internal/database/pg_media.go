@@ -0,0 +1,49 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type PgMediaStore struct {
+	db *sqlx.DB
+}
+
+func NewPgMediaStore(db *sqlx.DB) *PgMediaStore {
+	return &PgMediaStore{db: db}
+}
+
+func (s *PgMediaStore) Create(ctx context.Context, m *Media) error {
+	m.CreatedAt = time.Now().UTC()
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowxContext(ctx,
+		"INSERT INTO media(key, content_type, size, width, height, uploaded_by, created_at) VALUES($1, $2, $3, $4, $5, $6, $7) RETURNING id",
+		m.Key, m.ContentType, m.Size, m.Width, m.Height, m.UploadedBy, m.CreatedAt).Scan(&m.ID)
+	if err != nil {
+		return err
+	}
+
+	for i := range m.Variants {
+		v := &m.Variants[i]
+		v.MediaID = m.ID
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO media_variants(media_id, key, width, height) VALUES($1, $2, $3, $4)",
+			v.MediaID, v.Key, v.Width, v.Height)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *PgMediaStore) ListByKeys(ctx context.Context, keys []string) ([]Media, error) {
+	return listMediaByKeys(ctx, s.db, keys)
+}
@@ -0,0 +1,44 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type PgRelatedStore struct {
+	db *sqlx.DB
+}
+
+func NewPgRelatedStore(db *sqlx.DB) *PgRelatedStore {
+	return &PgRelatedStore{db: db}
+}
+
+func (s *PgRelatedStore) ListRelated(ctx context.Context, postID int64, limit int) ([]Post, error) {
+	posts := []Post{}
+	if err := s.db.SelectContext(ctx, &posts, s.db.Rebind(listRelatedQuery), postID, limit); err != nil {
+		return nil, err
+	}
+	return posts, loadTags(ctx, s.db, posts)
+}
+
+func (s *PgRelatedStore) ReplaceRelated(ctx context.Context, related []RelatedPost) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM related_posts"); err != nil {
+		return err
+	}
+	for _, rp := range related {
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO related_posts(post_id, related_id, score) VALUES($1, $2, $3)",
+			rp.PostID, rp.RelatedID, rp.Score)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
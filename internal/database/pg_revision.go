@@ -0,0 +1,47 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type PgRevisionStore struct {
+	db *sqlx.DB
+}
+
+func NewPgRevisionStore(db *sqlx.DB) *PgRevisionStore {
+	return &PgRevisionStore{db: db}
+}
+
+func (s *PgRevisionStore) ListRevisions(ctx context.Context, postID int64) ([]Revision, error) {
+	var revs []Revision
+	err := s.db.SelectContext(ctx, &revs,
+		"SELECT "+revisionColumns+" FROM post_revisions WHERE post_id = $1 ORDER BY id DESC", postID)
+	return revs, err
+}
+
+func (s *PgRevisionStore) GetRevision(ctx context.Context, postID, id int64) (*Revision, error) {
+	var rev Revision
+	err := s.db.GetContext(ctx, &rev,
+		"SELECT "+revisionColumns+" FROM post_revisions WHERE post_id = $1 AND id = $2", postID, id)
+	if err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
+
+func (s *PgRevisionStore) LatestRevisionID(ctx context.Context, postID int64) (int64, error) {
+	var id int64
+	err := s.db.GetContext(ctx, &id,
+		"SELECT id FROM post_revisions WHERE post_id = $1 ORDER BY id DESC LIMIT 1", postID)
+	return id, err
+}
+
+// insertRevision records the current title and body of p as saved by author.
+func (s *PgPostStore) insertRevision(ctx context.Context, tx *sqlx.Tx, p *Post, author string) error {
+	_, err := tx.ExecContext(ctx,
+		"INSERT INTO post_revisions(post_id, title, body, author, created_at) VALUES($1, $2, $3, $4, $5)",
+		p.ID, p.Title, p.Body, author, p.UpdatedAt)
+	return err
+}
@@ -0,0 +1,67 @@
+package database
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// PgSearchStore searches the generated search_vector column of posts,
+// which Postgres recomputes on every insert and update.
+type PgSearchStore struct {
+	db *sqlx.DB
+}
+
+func NewPgSearchStore(db *sqlx.DB) *PgSearchStore {
+	return &PgSearchStore{db: db}
+}
+
+func (s *PgSearchStore) Search(ctx context.Context, query string, limit, offset int) ([]SearchResult, error) {
+	q := tsQuery(query)
+	if q == "" {
+		return []SearchResult{}, nil
+	}
+
+	var results []SearchResult
+	err := s.db.SelectContext(ctx, &results, `
+		SELECT p.id, p.slug, p.title, p.published_at, p.updated_at, p.author, p.status,
+			ts_rank(p.search_vector, q) AS rank,
+			ts_headline('english', p.search_text, q,
+				'StartSel=' || chr(2) || ', StopSel=' || chr(3) || ', MaxWords=32, MinWords=12, MaxFragments=1') AS snippet
+		FROM posts p, to_tsquery('english', $1) q
+		WHERE p.search_vector @@ q AND p.status = 'published'
+		ORDER BY rank DESC, p.published_at DESC
+		LIMIT $2 OFFSET $3`, q, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return results, loadResultTags(ctx, s.db, results)
+}
+
+func (s *PgSearchStore) CountSearch(ctx context.Context, query string) (int, error) {
+	q := tsQuery(query)
+	if q == "" {
+		return 0, nil
+	}
+
+	var n int
+	err := s.db.GetContext(ctx, &n,
+		`SELECT count(*) FROM posts WHERE search_vector @@ to_tsquery('english', $1) AND status = 'published'`, q)
+	return n, err
+}
+
+func (s *PgSearchStore) SetSearchText(ctx context.Context, postID int64, text string) error {
+	_, err := s.db.ExecContext(ctx, "UPDATE posts SET search_text = $1 WHERE id = $2", text, postID)
+	return err
+}
+
+// tsQuery turns a user query into a tsquery matching posts that contain
+// every term, the last one as a prefix so results show up while typing.
+func tsQuery(query string) string {
+	terms := searchTerms(query)
+	if len(terms) > 0 {
+		terms[len(terms)-1] += ":*"
+	}
+	return strings.Join(terms, " & ")
+}
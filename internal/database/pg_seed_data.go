@@ -55,6 +55,8 @@ func InitPgDB(db *sqlx.DB) {
 		fmt.Println("Inserted user ID:", id)
 	}
 
+	initPgPosts(db)
+
 	// // ---- READ (single) ----
 	// var fetched User
 	// err = db.Get(&fetched, `SELECT * FROM users WHERE id = ?`, id)
@@ -87,3 +89,140 @@ func InitPgDB(db *sqlx.DB) {
 	// }
 	// fmt.Println("Deleted user ID:", id)
 }
+
+func initPgPosts(db *sqlx.DB) {
+	schema := `
+	CREATE TABLE IF NOT EXISTS posts (
+		id SERIAL PRIMARY KEY,
+		slug VARCHAR(200) NOT NULL UNIQUE,
+		title TEXT NOT NULL,
+		body TEXT NOT NULL,
+		published_at TIMESTAMPTZ NOT NULL,
+		updated_at TIMESTAMPTZ NOT NULL,
+		author VARCHAR(100) NOT NULL DEFAULT '',
+		status VARCHAR(20) NOT NULL DEFAULT 'published',
+		search_text TEXT NOT NULL DEFAULT '',
+		-- Generated from the title and the search text, so it follows
+		-- every write.
+		search_vector tsvector GENERATED ALWAYS AS (
+			setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+			setweight(to_tsvector('english', coalesce(search_text, '')), 'B')
+		) STORED
+	);
+	CREATE INDEX IF NOT EXISTS posts_published_at_idx ON posts (published_at, id);
+	CREATE INDEX IF NOT EXISTS posts_status_idx ON posts (status, published_at);
+	CREATE INDEX IF NOT EXISTS posts_search_vector_idx ON posts USING GIN (search_vector);
+
+	CREATE TABLE IF NOT EXISTS tags (
+		id SERIAL PRIMARY KEY,
+		name VARCHAR(100) NOT NULL UNIQUE
+	);
+
+	CREATE TABLE IF NOT EXISTS post_tags (
+		post_id INT NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+		tag_id INT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+		PRIMARY KEY (post_id, tag_id)
+	);
+	CREATE INDEX IF NOT EXISTS post_tags_tag_id_idx ON post_tags (tag_id);
+
+	CREATE TABLE IF NOT EXISTS post_revisions (
+		id SERIAL PRIMARY KEY,
+		post_id INT NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+		title TEXT NOT NULL,
+		body TEXT NOT NULL,
+		author VARCHAR(100) NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS post_revisions_post_id_idx ON post_revisions (post_id, id);
+
+	CREATE TABLE IF NOT EXISTS media (
+		id SERIAL PRIMARY KEY,
+		key VARCHAR(300) NOT NULL UNIQUE,
+		content_type VARCHAR(100) NOT NULL,
+		size BIGINT NOT NULL,
+		width INT NOT NULL DEFAULT 0,
+		height INT NOT NULL DEFAULT 0,
+		uploaded_by VARCHAR(100) NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS media_variants (
+		media_id INT NOT NULL REFERENCES media(id) ON DELETE CASCADE,
+		key VARCHAR(300) NOT NULL UNIQUE,
+		width INT NOT NULL,
+		height INT NOT NULL,
+		PRIMARY KEY (media_id, width)
+	);
+
+	CREATE TABLE IF NOT EXISTS comments (
+		id SERIAL PRIMARY KEY,
+		post_id INT NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+		parent_id INT REFERENCES comments(id) ON DELETE CASCADE,
+		author_id VARCHAR(200) NOT NULL,
+		author_name VARCHAR(100) NOT NULL,
+		author_avatar TEXT NOT NULL DEFAULT '',
+		body TEXT NOT NULL,
+		status VARCHAR(20) NOT NULL DEFAULT 'pending',
+		created_at TIMESTAMPTZ NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS comments_post_id_idx ON comments (post_id, status, created_at);
+	CREATE INDEX IF NOT EXISTS comments_status_idx ON comments (status, created_at);
+
+	CREATE TABLE IF NOT EXISTS series (
+		id SERIAL PRIMARY KEY,
+		slug VARCHAR(200) NOT NULL UNIQUE,
+		title TEXT NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS series_posts (
+		series_id INT NOT NULL REFERENCES series(id) ON DELETE CASCADE,
+		post_id INT NOT NULL PRIMARY KEY REFERENCES posts(id) ON DELETE CASCADE,
+		position INT NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS series_posts_series_id_idx ON series_posts (series_id, position);
+
+	CREATE TABLE IF NOT EXISTS related_posts (
+		post_id INT NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+		related_id INT NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+		score DOUBLE PRECISION NOT NULL,
+		PRIMARY KEY (post_id, related_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS accounts (
+		id SERIAL PRIMARY KEY,
+		email VARCHAR(320) NOT NULL DEFAULT '',
+		name VARCHAR(200) NOT NULL DEFAULT '',
+		first_name VARCHAR(100) NOT NULL DEFAULT '',
+		last_name VARCHAR(100) NOT NULL DEFAULT '',
+		avatar_url TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ NOT NULL,
+		last_login_at TIMESTAMPTZ NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS identities (
+		provider VARCHAR(50) NOT NULL,
+		provider_user_id VARCHAR(200) NOT NULL,
+		account_id INT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+		email VARCHAR(320) NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ NOT NULL,
+		last_login_at TIMESTAMPTZ NOT NULL,
+		PRIMARY KEY (provider, provider_user_id)
+	);
+	CREATE INDEX IF NOT EXISTS identities_account_id_idx ON identities (account_id);
+
+	CREATE TABLE IF NOT EXISTS sessions (
+		id VARCHAR(64) PRIMARY KEY,
+		data BYTEA NOT NULL,
+		account_id INT REFERENCES accounts(id) ON DELETE CASCADE,
+		ip VARCHAR(64) NOT NULL DEFAULT '',
+		user_agent TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ NOT NULL,
+		updated_at TIMESTAMPTZ NOT NULL,
+		last_seen_at TIMESTAMPTZ NOT NULL,
+		expires_at TIMESTAMPTZ NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS sessions_expires_at_idx ON sessions (expires_at);
+	CREATE INDEX IF NOT EXISTS sessions_account_id_idx ON sessions (account_id);`
+	db.MustExec(schema)
+}
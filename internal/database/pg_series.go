@@ -0,0 +1,91 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type PgSeriesStore struct {
+	db *sqlx.DB
+}
+
+func NewPgSeriesStore(db *sqlx.DB) *PgSeriesStore {
+	return &PgSeriesStore{db: db}
+}
+
+func (s *PgSeriesStore) GetBySlug(ctx context.Context, slug string) (*Series, error) {
+	var series Series
+	err := s.db.GetContext(ctx, &series, "SELECT id, slug, title FROM series WHERE slug = $1", slug)
+	if err != nil {
+		return nil, err
+	}
+	return &series, loadSeriesPosts(ctx, s.db, &series)
+}
+
+func (s *PgSeriesStore) ForPost(ctx context.Context, postID int64) (*Series, error) {
+	var series Series
+	err := s.db.GetContext(ctx, &series, `
+		SELECT s.id, s.slug, s.title, sp.position
+		FROM series s JOIN series_posts sp ON sp.series_id = s.id
+		WHERE sp.post_id = $1`, postID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &series, loadSeriesPosts(ctx, s.db, &series)
+}
+
+func (s *PgSeriesStore) List(ctx context.Context) ([]Series, error) {
+	var series []Series
+	err := s.db.SelectContext(ctx, &series, listSeriesQuery)
+	return series, err
+}
+
+func (s *PgSeriesStore) SetPostSeries(ctx context.Context, postID int64, slug, title string, position int) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM series_posts WHERE post_id = $1", postID); err != nil {
+		return err
+	}
+
+	if slug != "" {
+		var seriesID int64
+		err := tx.GetContext(ctx, &seriesID, `
+			INSERT INTO series(slug, title, created_at) VALUES($1, $2, $3)
+			ON CONFLICT(slug) DO UPDATE SET title = excluded.title
+			RETURNING id`,
+			slug, title, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+		if position <= 0 {
+			err := tx.GetContext(ctx, &position,
+				"SELECT coalesce(max(position), 0) + 1 FROM series_posts WHERE series_id = $1", seriesID)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO series_posts(series_id, post_id, position) VALUES($1, $2, $3)",
+			seriesID, postID, position)
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM series WHERE id NOT IN (SELECT series_id FROM series_posts)"); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
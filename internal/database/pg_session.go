@@ -0,0 +1,81 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type PgSessionStore struct {
+	db *sqlx.DB
+}
+
+func NewPgSessionStore(db *sqlx.DB) *PgSessionStore {
+	return &PgSessionStore{db: db}
+}
+
+func (s *PgSessionStore) GetSession(ctx context.Context, id string) (*Session, error) {
+	var sess Session
+	err := s.db.GetContext(ctx, &sess,
+		"SELECT "+sessionColumns+" FROM sessions WHERE id = $1 AND expires_at > $2", id, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	return &sess, nil
+}
+
+func (s *PgSessionStore) SaveSession(ctx context.Context, sess *Session) error {
+	now := time.Now().UTC()
+	if sess.CreatedAt.IsZero() {
+		sess.CreatedAt = now
+	}
+	sess.UpdatedAt = now
+	sess.LastSeenAt = now
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO sessions(id, data, account_id, ip, user_agent, created_at, updated_at, last_seen_at, expires_at)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT(id) DO UPDATE SET data = excluded.data, account_id = excluded.account_id,
+			ip = excluded.ip, user_agent = excluded.user_agent, updated_at = excluded.updated_at,
+			last_seen_at = excluded.last_seen_at, expires_at = excluded.expires_at`,
+		sess.ID, sess.Data, sess.AccountID, sess.IP, sess.UserAgent,
+		sess.CreatedAt, sess.UpdatedAt, sess.LastSeenAt, sess.ExpiresAt.UTC())
+	return err
+}
+
+func (s *PgSessionStore) TouchSession(ctx context.Context, id, ip, userAgent string, seenAt time.Time) error {
+	_, err := s.db.ExecContext(ctx,
+		"UPDATE sessions SET ip = $1, user_agent = $2, last_seen_at = $3 WHERE id = $4",
+		ip, userAgent, seenAt.UTC(), id)
+	return err
+}
+
+func (s *PgSessionStore) DeleteSession(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, "DELETE FROM sessions WHERE id = $1", id)
+	return err
+}
+
+func (s *PgSessionStore) ListAccountSessions(ctx context.Context, accountID int64) ([]Session, error) {
+	var sessions []Session
+	err := s.db.SelectContext(ctx, &sessions,
+		"SELECT "+sessionColumns+" FROM sessions WHERE account_id = $1 AND expires_at > $2 ORDER BY last_seen_at DESC",
+		accountID, time.Now().UTC())
+	return sessions, err
+}
+
+func (s *PgSessionStore) DeleteAccountSessions(ctx context.Context, accountID int64) (int64, error) {
+	res, err := s.db.ExecContext(ctx, "DELETE FROM sessions WHERE account_id = $1", accountID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (s *PgSessionStore) DeleteExpiredSessions(ctx context.Context, now time.Time) (int64, error) {
+	res, err := s.db.ExecContext(ctx, "DELETE FROM sessions WHERE expires_at <= $1", now.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
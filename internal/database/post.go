@@ -0,0 +1,135 @@
+package database
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultWordsPerMinute is the reading speed used when READING_WPM is not set.
+const DefaultWordsPerMinute = 200
+
+// Post statuses. Only published posts show up on the public routes; a
+// scheduled post is published once its PublishedAt has passed.
+const (
+	StatusDraft     = "draft"
+	StatusScheduled = "scheduled"
+	StatusPublished = "published"
+	StatusArchived  = "archived"
+)
+
+// Statuses lists the valid post statuses.
+var Statuses = []string{StatusDraft, StatusScheduled, StatusPublished, StatusArchived}
+
+type Post struct {
+	ID          int64     `db:"id" json:"id"`
+	Slug        string    `db:"slug" json:"slug"`
+	Title       string    `db:"title" json:"title"`
+	Body        string    `db:"body" json:"body"`
+	Tags        []string  `db:"-" json:"tags"`
+	PublishedAt time.Time `db:"published_at" json:"published_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+	Author      string    `db:"author" json:"author"`
+	Status      string    `db:"status" json:"status"`
+	// SearchText is the plain text of Body the search index holds. It is
+	// only written, by Create and Update.
+	SearchText string `db:"search_text" json:"-"`
+
+	// WordCount, ReadingTime and Summary are derived from the rendered
+	// body, see SetReadingStats.
+	WordCount   int    `db:"-" json:"word_count"`
+	ReadingTime int    `db:"-" json:"reading_time"`
+	Summary     string `db:"-" json:"summary"`
+}
+
+// IsPublished reports whether the post is visible on the public routes.
+func (p *Post) IsPublished() bool {
+	return p.Status == StatusPublished
+}
+
+// URL returns the public path of the post.
+func (p *Post) URL() string {
+	return "/posts/" + p.Slug
+}
+
+// SetReadingStats records the word count of the rendered body and the
+// reading time, in whole minutes, at wpm words per minute.
+func (p *Post) SetReadingStats(words, wpm int) {
+	if wpm <= 0 {
+		wpm = DefaultWordsPerMinute
+	}
+	p.WordCount = words
+	p.ReadingTime = (words + wpm - 1) / wpm
+	if p.ReadingTime < 1 {
+		p.ReadingTime = 1
+	}
+}
+
+// PostStore reads and writes posts. GetBySlug, GetByID and ListAll return
+// posts of any status, every other listing only returns published posts.
+type PostStore interface {
+	GetBySlug(ctx context.Context, slug string) (*Post, error)
+	GetByID(ctx context.Context, id int64) (*Post, error)
+	// ListAll returns up to limit posts of any status, most recently
+	// updated first, skipping the first offset.
+	ListAll(ctx context.Context, limit, offset int) ([]Post, error)
+	CountAll(ctx context.Context) (int, error)
+	// List returns up to limit posts, newest first, skipping the first offset.
+	List(ctx context.Context, limit, offset int) ([]Post, error)
+	Count(ctx context.Context) (int, error)
+	// ListIndex returns every post, newest first, without its body.
+	ListIndex(ctx context.Context) ([]Post, error)
+	// ListTags returns every tag in use with its number of posts, by name.
+	ListTags(ctx context.Context) ([]TagCount, error)
+	// ListByTag is like List, restricted to the posts tagged with tag.
+	ListByTag(ctx context.Context, tag string, limit, offset int) ([]Post, error)
+	CountByTag(ctx context.Context, tag string) (int, error)
+	// ListMonths returns every month with published posts, newest first.
+	ListMonths(ctx context.Context) ([]MonthCount, error)
+	// ListByDate is like List, restricted to the posts published from
+	// from, included, to to, excluded.
+	ListByDate(ctx context.Context, from, to time.Time, limit, offset int) ([]Post, error)
+	CountByDate(ctx context.Context, from, to time.Time) (int, error)
+	// Create inserts p. An empty Status means published. Create and Update
+	// both record a Revision of p, saved by savedBy, and stamp posts that
+	// are not drafts and have no PublishedAt with the current time.
+	Create(ctx context.Context, p *Post, savedBy string) error
+	// Update saves every field of p, which must exist, and its tags.
+	Update(ctx context.Context, p *Post, savedBy string) error
+	Delete(ctx context.Context, id int64) error
+	// Adjacent returns the posts published right before and right after p.
+	// Either of them is nil when p is the first or the last post.
+	Adjacent(ctx context.Context, p *Post) (prev *Post, next *Post, err error)
+	// PublishDue publishes the scheduled posts whose publish time is not
+	// after now and returns how many there were.
+	PublishDue(ctx context.Context, now time.Time) (int64, error)
+}
+
+const postColumns = "id, slug, title, body, published_at, updated_at, author, status"
+
+// prepareUpdate normalizes a post about to be saved.
+func prepareUpdate(p *Post) {
+	now := time.Now().UTC()
+	stampPublished(p, now)
+	p.UpdatedAt = now
+	p.Tags = CleanTags(p.Tags)
+}
+
+// prepareCreate fills in the defaults of a post about to be inserted.
+func prepareCreate(p *Post) {
+	now := time.Now().UTC()
+	if p.Status == "" {
+		p.Status = StatusPublished
+	}
+	stampPublished(p, now)
+	p.UpdatedAt = now
+	p.Tags = CleanTags(p.Tags)
+}
+
+// stampPublished dates a post that leaves the drafts without a publish
+// date. Drafts keep a zero PublishedAt until then.
+func stampPublished(p *Post, now time.Time) {
+	if p.PublishedAt.IsZero() && p.Status != StatusDraft {
+		p.PublishedAt = now
+	}
+	p.PublishedAt = p.PublishedAt.UTC()
+}
@@ -0,0 +1,55 @@
+package database_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gochi-demo/internal/database"
+	"github.com/gochi-demo/internal/database/dbtest"
+)
+
+func TestDraftPublishedAt(t *testing.T) {
+	ctx := context.Background()
+
+	for _, b := range dbtest.Backends(t) {
+		t.Run(b.Name, func(t *testing.T) {
+			p := &database.Post{Slug: dbtest.ID(t), Title: "Draft", Body: "Not yet.", Status: database.StatusDraft}
+			if err := b.Posts.Create(ctx, p, "test"); err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { b.Posts.Delete(ctx, p.ID) })
+
+			got, err := b.Posts.GetByID(ctx, p.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.PublishedAt.IsZero() {
+				t.Fatalf("draft PublishedAt = %v, want zero", got.PublishedAt)
+			}
+
+			got.Body = "Still not yet."
+			if err := b.Posts.Update(ctx, got, "test"); err != nil {
+				t.Fatal(err)
+			}
+			if got, err = b.Posts.GetByID(ctx, p.ID); err != nil {
+				t.Fatal(err)
+			}
+			if !got.PublishedAt.IsZero() {
+				t.Fatalf("updated draft PublishedAt = %v, want zero", got.PublishedAt)
+			}
+
+			before := time.Now().Add(-time.Second)
+			got.Status = database.StatusPublished
+			if err := b.Posts.Update(ctx, got, "test"); err != nil {
+				t.Fatal(err)
+			}
+			if got, err = b.Posts.GetByID(ctx, p.ID); err != nil {
+				t.Fatal(err)
+			}
+			if got.PublishedAt.Before(before) || got.PublishedAt.After(time.Now()) {
+				t.Errorf("published PublishedAt = %v, want the publish time", got.PublishedAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package database
+
+import "context"
+
+// RelatedPost is a precomputed link from a post to a related one.
+type RelatedPost struct {
+	PostID    int64   `db:"post_id"`
+	RelatedID int64   `db:"related_id"`
+	Score     float64 `db:"score"`
+}
+
+// RelatedStore caches the related posts of every post.
+type RelatedStore interface {
+	// ListRelated returns up to limit published posts related to the post
+	// with the given id, best match first.
+	ListRelated(ctx context.Context, postID int64, limit int) ([]Post, error)
+	// ReplaceRelated swaps the whole cache for related.
+	ReplaceRelated(ctx context.Context, related []RelatedPost) error
+}
+
+// listRelatedQuery selects the published posts related to a post.
+const listRelatedQuery = `
+	SELECT p.id, p.slug, p.title, p.body, p.published_at, p.updated_at, p.author, p.status
+	FROM related_posts rp
+	JOIN posts p ON p.id = rp.related_id
+	WHERE rp.post_id = ? AND p.status = 'published'
+	ORDER BY rp.score DESC, p.id DESC
+	LIMIT ?`
@@ -0,0 +1,64 @@
+package database_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/gochi-demo/internal/database"
+	"github.com/gochi-demo/internal/database/dbtest"
+)
+
+func TestListRelated(t *testing.T) {
+	ctx := context.Background()
+
+	for _, b := range dbtest.Backends(t) {
+		t.Run(b.Name, func(t *testing.T) {
+			var ids []int64
+			for _, status := range []string{
+				database.StatusPublished, database.StatusPublished, database.StatusPublished,
+				database.StatusPublished, database.StatusDraft,
+			} {
+				p := &database.Post{Slug: dbtest.ID(t), Title: "Related", Body: "Text.", Status: status}
+				if err := b.Posts.Create(ctx, p, "test"); err != nil {
+					t.Fatal(err)
+				}
+				t.Cleanup(func() { b.Posts.Delete(ctx, p.ID) })
+				ids = append(ids, p.ID)
+			}
+			post, draft := ids[0], ids[4]
+
+			err := b.Related.ReplaceRelated(ctx, []database.RelatedPost{
+				{PostID: post, RelatedID: ids[1], Score: 0.2},
+				{PostID: post, RelatedID: ids[2], Score: 0.9},
+				{PostID: post, RelatedID: ids[3], Score: 0.5},
+				{PostID: post, RelatedID: draft, Score: 1},
+				{PostID: ids[1], RelatedID: post, Score: 0.2},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tests := []struct {
+				limit int
+				want  []int64
+			}{
+				{5, []int64{ids[2], ids[3], ids[1]}},
+				{2, []int64{ids[2], ids[3]}},
+			}
+			for _, tt := range tests {
+				posts, err := b.Related.ListRelated(ctx, post, tt.limit)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var got []int64
+				for _, p := range posts {
+					got = append(got, p.ID)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("ListRelated(limit %d) = %v; want %v", tt.limit, got, tt.want)
+				}
+			}
+		})
+	}
+}
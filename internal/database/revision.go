@@ -0,0 +1,32 @@
+package database
+
+import (
+	"context"
+	"time"
+)
+
+// Revision is an immutable copy of the title and body of a post, taken
+// every time the post is saved.
+type Revision struct {
+	ID        int64     `db:"id" json:"id"`
+	PostID    int64     `db:"post_id" json:"post_id"`
+	Title     string    `db:"title" json:"title"`
+	Body      string    `db:"body" json:"body"`
+	Author    string    `db:"author" json:"author"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// RevisionStore reads the revisions written by PostStore.Create and
+// PostStore.Update.
+type RevisionStore interface {
+	// ListRevisions returns the revisions of a post, newest first.
+	ListRevisions(ctx context.Context, postID int64) ([]Revision, error)
+	// GetRevision returns sql.ErrNoRows unless the revision belongs to the
+	// post.
+	GetRevision(ctx context.Context, postID, id int64) (*Revision, error)
+	// LatestRevisionID returns the id of the newest revision of a post, or
+	// sql.ErrNoRows when it has none.
+	LatestRevisionID(ctx context.Context, postID int64) (int64, error)
+}
+
+const revisionColumns = "id, post_id, title, body, author, created_at"
@@ -0,0 +1,55 @@
+package database
+
+import (
+	"context"
+	"html/template"
+	"strings"
+	"unicode"
+)
+
+// Snippets come back from the database with the matched terms wrapped in
+// these control characters, which can't appear in a post.
+const (
+	highlightStart = "\x02"
+	highlightEnd   = "\x03"
+)
+
+type SearchResult struct {
+	Post
+	// Rank orders the results, higher is more relevant.
+	Rank    float64 `db:"rank" json:"rank"`
+	Snippet string  `db:"snippet" json:"-"`
+}
+
+// Highlighted returns the snippet as HTML, matched terms wrapped in <mark>.
+func (r *SearchResult) Highlighted() template.HTML {
+	s := template.HTMLEscapeString(r.Snippet)
+	s = strings.ReplaceAll(s, highlightStart, "<mark>")
+	s = strings.ReplaceAll(s, highlightEnd, "</mark>")
+	return template.HTML(s)
+}
+
+// PlainSnippet returns the snippet without highlight markers.
+func (r *SearchResult) PlainSnippet() string {
+	return strings.NewReplacer(highlightStart, "", highlightEnd, "").Replace(r.Snippet)
+}
+
+// SearchStore runs full-text queries against posts. The index follows every
+// insert and update of the posts table.
+type SearchStore interface {
+	// Search returns up to limit posts matching query, best match first,
+	// skipping the first offset.
+	Search(ctx context.Context, query string, limit, offset int) ([]SearchResult, error)
+	CountSearch(ctx context.Context, query string) (int, error)
+	// SetSearchText replaces the SearchText of the post with the given id,
+	// for reindexing posts without saving them.
+	SetSearchText(ctx context.Context, postID int64, text string) error
+}
+
+// searchTerms splits a user query into words, dropping the punctuation
+// that full-text query parsers would choke on.
+func searchTerms(query string) []string {
+	return strings.FieldsFunc(query, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,64 @@
+package database_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gochi-demo/internal/database"
+	"github.com/gochi-demo/internal/database/dbtest"
+)
+
+// snippetOf returns the plain snippet of the post with the given id in the
+// results of query, or "" when the post is not found.
+func snippetOf(t *testing.T, search database.SearchStore, query string, id int64) string {
+	t.Helper()
+	results, err := search.Search(context.Background(), query, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if r.ID == id {
+			return r.PlainSnippet()
+		}
+	}
+	return ""
+}
+
+func TestSearchIndexesSearchText(t *testing.T) {
+	ctx := context.Background()
+
+	for _, b := range dbtest.Backends(t) {
+		t.Run(b.Name, func(t *testing.T) {
+			p := &database.Post{
+				Slug:       dbtest.ID(t),
+				Title:      "Brewing",
+				Body:       "## Zymurgy basics\n\nRead [the zymurgy guide](https://example.com/guide) first.",
+				SearchText: "Zymurgy basics\nRead the zymurgy guide first.",
+				Status:     database.StatusPublished,
+			}
+			if err := b.Posts.Create(ctx, p, "test"); err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { b.Posts.Delete(ctx, p.ID) })
+
+			snippet := snippetOf(t, b.Search, "zymurgy", p.ID)
+			if !strings.Contains(snippet, "the zymurgy guide first") {
+				t.Errorf("snippet %q is not taken from the search text", snippet)
+			}
+			if got := snippetOf(t, b.Search, "example", p.ID); got != "" {
+				t.Errorf("found by the link target only in the body, with snippet %q", got)
+			}
+
+			if err := b.Search.SetSearchText(ctx, p.ID, "Quince and mead."); err != nil {
+				t.Fatal(err)
+			}
+			if got := snippetOf(t, b.Search, "quince", p.ID); !strings.Contains(got, "Quince and mead") {
+				t.Errorf("snippet after SetSearchText = %q", got)
+			}
+			if got := snippetOf(t, b.Search, "zymurgy", p.ID); got != "" {
+				t.Errorf("still found by the old search text, with snippet %q", got)
+			}
+		})
+	}
+}
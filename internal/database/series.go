@@ -0,0 +1,98 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Series groups posts, such as the parts of a tutorial, in a set order.
+// A post belongs to at most one series.
+type Series struct {
+	ID    int64  `db:"id" json:"id"`
+	Slug  string `db:"slug" json:"slug"`
+	Title string `db:"title" json:"title"`
+
+	// Position is the position of the post ForPost was asked about and
+	// UpdatedAt, only loaded by List, the last update of its posts.
+	Position  int       `db:"position" json:"-"`
+	UpdatedAt time.Time `db:"updated_at" json:"-"`
+
+	// Posts are the published posts of the series, in order.
+	Posts []Post `db:"-" json:"posts"`
+}
+
+// URL returns the public path of the series.
+func (s *Series) URL() string {
+	return "/series/" + s.Slug
+}
+
+// Part returns the 1-based part number of the post with the given id
+// among the published posts of the series, or 0 when it isn't one of them.
+func (s *Series) Part(postID int64) int {
+	for i := range s.Posts {
+		if s.Posts[i].ID == postID {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// Adjacent returns the parts right before and right after the post with
+// the given id. Either of them is nil at the ends of the series.
+func (s *Series) Adjacent(postID int64) (prev *Post, next *Post) {
+	n := s.Part(postID)
+	if n == 0 {
+		return nil, nil
+	}
+	if n > 1 {
+		prev = &s.Posts[n-2]
+	}
+	if n < len(s.Posts) {
+		next = &s.Posts[n]
+	}
+	return prev, next
+}
+
+type SeriesStore interface {
+	// GetBySlug returns the series with its published posts.
+	GetBySlug(ctx context.Context, slug string) (*Series, error)
+	// ForPost returns the series of the post with the given id, with its
+	// published posts, or nil when the post isn't part of a series.
+	ForPost(ctx context.Context, postID int64) (*Series, error)
+	// List returns every series that has published posts, by title,
+	// without the posts.
+	List(ctx context.Context) ([]Series, error)
+	// SetPostSeries moves the post with the given id to the series with
+	// the given slug, created with title if needed, at position. A zero
+	// position puts the post last. An empty slug takes the post out of
+	// its series. Series left without posts are deleted.
+	SetPostSeries(ctx context.Context, postID int64, slug, title string, position int) error
+}
+
+// loadSeriesPosts fills in the published Posts of series, in order.
+func loadSeriesPosts(ctx context.Context, db *sqlx.DB, series *Series) error {
+	series.Posts = nil
+	err := db.SelectContext(ctx, &series.Posts, db.Rebind(`
+	SELECT p.id, p.slug, p.title, p.body, p.published_at, p.updated_at, p.author, p.status
+	FROM posts p
+	JOIN series_posts sp ON sp.post_id = p.id
+	WHERE sp.series_id = ? AND p.status = 'published'
+	ORDER BY sp.position, p.published_at, p.id`), series.ID)
+	if err != nil {
+		return err
+	}
+	return loadTags(ctx, db, series.Posts)
+}
+
+// listSeriesQuery selects the series that have published posts, with the
+// last update of those posts.
+const listSeriesQuery = `
+	SELECT s.id, s.slug, s.title, max(p.updated_at) AS updated_at
+	FROM series s
+	JOIN series_posts sp ON sp.series_id = s.id
+	JOIN posts p ON p.id = sp.post_id
+	WHERE p.status = 'published'
+	GROUP BY s.id, s.slug, s.title
+	ORDER BY s.title`
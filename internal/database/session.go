@@ -0,0 +1,53 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// Session is a server-side session. The browser cookie only holds its id.
+type Session struct {
+	ID string `db:"id"`
+	// Data holds the encoded session values.
+	Data []byte `db:"data"`
+	// AccountID is the account signed in to the session, if any.
+	AccountID *int64 `db:"account_id"`
+	// IP and UserAgent are those of the last request of the session.
+	IP         string    `db:"ip"`
+	UserAgent  string    `db:"user_agent"`
+	CreatedAt  time.Time `db:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at"`
+	LastSeenAt time.Time `db:"last_seen_at"`
+	ExpiresAt  time.Time `db:"expires_at"`
+}
+
+// Handle identifies the session in pages and URLs. Unlike the id, it can't
+// be used to sign in.
+func (s *Session) Handle() string {
+	sum := sha256.Sum256([]byte(s.ID))
+	return hex.EncodeToString(sum[:8])
+}
+
+type SessionStore interface {
+	// GetSession returns sql.ErrNoRows for unknown and expired sessions.
+	GetSession(ctx context.Context, id string) (*Session, error)
+	// SaveSession creates the session or replaces its data, account,
+	// activity and expiry.
+	SaveSession(ctx context.Context, s *Session) error
+	// TouchSession records a request of the session at seenAt.
+	TouchSession(ctx context.Context, id, ip, userAgent string, seenAt time.Time) error
+	DeleteSession(ctx context.Context, id string) error
+	// ListAccountSessions returns the unexpired sessions of an account,
+	// most recently seen first.
+	ListAccountSessions(ctx context.Context, accountID int64) ([]Session, error)
+	// DeleteAccountSessions signs an account out everywhere and returns the
+	// number of sessions deleted.
+	DeleteAccountSessions(ctx context.Context, accountID int64) (int64, error)
+	// DeleteExpiredSessions removes the sessions expired at now and returns
+	// how many there were.
+	DeleteExpiredSessions(ctx context.Context, now time.Time) (int64, error)
+}
+
+const sessionColumns = "id, data, account_id, ip, user_agent, created_at, updated_at, last_seen_at, expires_at"
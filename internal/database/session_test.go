@@ -0,0 +1,147 @@
+package database_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gochi-demo/internal/database"
+	"github.com/gochi-demo/internal/database/dbtest"
+)
+
+// saveTestSession saves a session expiring at expires and deletes it at the
+// end of the test.
+func saveTestSession(t *testing.T, st database.SessionStore, expires time.Time, data string) string {
+	t.Helper()
+	id := dbtest.ID(t)
+	if err := st.SaveSession(context.Background(), &database.Session{ID: id, Data: []byte(data), ExpiresAt: expires}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { st.DeleteSession(context.Background(), id) })
+	return id
+}
+
+func TestGetSession(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name    string
+		expires time.Duration
+		found   bool
+	}{
+		{"live", time.Hour, true},
+		{"expired", -time.Hour, false},
+		{"just expired", -time.Second, false},
+	}
+
+	for _, b := range dbtest.Backends(t) {
+		for _, tt := range tests {
+			t.Run(b.Name+"/"+tt.name, func(t *testing.T) {
+				id := saveTestSession(t, b.Sessions, time.Now().Add(tt.expires), "data")
+
+				s, err := b.Sessions.GetSession(ctx, id)
+				if !tt.found {
+					if !errors.Is(err, sql.ErrNoRows) {
+						t.Fatalf("GetSession = %v, %v; want sql.ErrNoRows", s, err)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				if s.ID != id || string(s.Data) != "data" {
+					t.Errorf("GetSession = %q with %q; want %q with %q", s.ID, s.Data, id, "data")
+				}
+			})
+		}
+		t.Run(b.Name+"/unknown", func(t *testing.T) {
+			if _, err := b.Sessions.GetSession(ctx, dbtest.ID(t)); !errors.Is(err, sql.ErrNoRows) {
+				t.Fatalf("GetSession = %v; want sql.ErrNoRows", err)
+			}
+		})
+	}
+}
+
+func TestSaveSessionUpsert(t *testing.T) {
+	ctx := context.Background()
+	for _, b := range dbtest.Backends(t) {
+		t.Run(b.Name, func(t *testing.T) {
+			id := saveTestSession(t, b.Sessions, time.Now().Add(time.Hour), "first")
+			first, err := b.Sessions.GetSession(ctx, id)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expires := time.Now().Add(2 * time.Hour).UTC().Truncate(time.Second)
+			err = b.Sessions.SaveSession(ctx, &database.Session{
+				ID:        id,
+				Data:      []byte("second"),
+				IP:        "192.0.2.1",
+				UserAgent: "test",
+				ExpiresAt: expires,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			s, err := b.Sessions.GetSession(ctx, id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(s.Data) != "second" || s.IP != "192.0.2.1" || s.UserAgent != "test" {
+				t.Errorf("saved session = %q, %q, %q; want the second save", s.Data, s.IP, s.UserAgent)
+			}
+			if !s.ExpiresAt.Equal(expires) {
+				t.Errorf("ExpiresAt = %v; want %v", s.ExpiresAt, expires)
+			}
+			if !s.CreatedAt.Equal(first.CreatedAt) {
+				t.Errorf("CreatedAt = %v; want it kept at %v", s.CreatedAt, first.CreatedAt)
+			}
+
+			var n int
+			if err := b.DB.Get(&n, b.DB.Rebind("SELECT COUNT(*) FROM sessions WHERE id = ?"), id); err != nil {
+				t.Fatal(err)
+			}
+			if n != 1 {
+				t.Errorf("%d rows for the session; want 1", n)
+			}
+		})
+	}
+}
+
+func TestDeleteExpiredSessions(t *testing.T) {
+	ctx := context.Background()
+	for _, b := range dbtest.Backends(t) {
+		t.Run(b.Name, func(t *testing.T) {
+			now := time.Now()
+			expired := saveTestSession(t, b.Sessions, now.Add(-time.Minute), "expired")
+			live := saveTestSession(t, b.Sessions, now.Add(time.Minute), "live")
+
+			n, err := b.Sessions.DeleteExpiredSessions(ctx, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n < 1 {
+				t.Errorf("DeleteExpiredSessions = %d; want at least 1", n)
+			}
+
+			tests := []struct {
+				id   string
+				left int
+			}{
+				{expired, 0},
+				{live, 1},
+			}
+			for _, tt := range tests {
+				var left int
+				if err := b.DB.Get(&left, b.DB.Rebind("SELECT COUNT(*) FROM sessions WHERE id = ?"), tt.id); err != nil {
+					t.Fatal(err)
+				}
+				if left != tt.left {
+					t.Errorf("%d rows left for %s; want %d", left, tt.id, tt.left)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type SQLiteAccountStore struct {
+	db *sqlx.DB
+}
+
+func NewSQLiteAccountStore(db *sqlx.DB) *SQLiteAccountStore {
+	return &SQLiteAccountStore{db: db}
+}
+
+func (s *SQLiteAccountStore) GetByID(ctx context.Context, id int64) (*Account, error) {
+	var a Account
+	err := s.db.GetContext(ctx, &a, "SELECT "+accountColumns+" FROM accounts WHERE id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (s *SQLiteAccountStore) List(ctx context.Context) ([]Account, error) {
+	var accounts []Account
+	err := s.db.SelectContext(ctx, &accounts, "SELECT "+accountColumns+" FROM accounts ORDER BY last_login_at DESC")
+	return accounts, err
+}
+
+func (s *SQLiteAccountStore) UpsertLogin(ctx context.Context, id *Identity, profile *Account) (*Account, error) {
+	now := time.Now().UTC()
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var accountID int64
+	err = tx.GetContext(ctx, &accountID,
+		"SELECT account_id FROM identities WHERE provider = ? AND provider_user_id = ?",
+		id.Provider, id.ProviderUserID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		res, err := tx.ExecContext(ctx, `
+			INSERT INTO accounts(email, name, first_name, last_name, avatar_url, created_at, last_login_at)
+			VALUES(?, ?, ?, ?, ?, ?, ?)`,
+			profile.Email, profile.Name, profile.FirstName, profile.LastName, profile.AvatarURL, now, now)
+		if err != nil {
+			return nil, err
+		}
+		if accountID, err = res.LastInsertId(); err != nil {
+			return nil, err
+		}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO identities(provider, provider_user_id, account_id, email, created_at, last_login_at)
+			VALUES(?, ?, ?, ?, ?, ?)`,
+			id.Provider, id.ProviderUserID, accountID, id.Email, now, now)
+		if err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	default:
+		_, err = tx.ExecContext(ctx, `
+			UPDATE accounts SET email = ?, name = ?, first_name = ?, last_name = ?, avatar_url = ?, last_login_at = ?
+			WHERE id = ?`,
+			profile.Email, profile.Name, profile.FirstName, profile.LastName, profile.AvatarURL, now, accountID)
+		if err != nil {
+			return nil, err
+		}
+		_, err = tx.ExecContext(ctx,
+			"UPDATE identities SET email = ?, last_login_at = ? WHERE provider = ? AND provider_user_id = ?",
+			id.Email, now, id.Provider, id.ProviderUserID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var a Account
+	err = tx.GetContext(ctx, &a, "SELECT "+accountColumns+" FROM accounts WHERE id = ?", accountID)
+	if err != nil {
+		return nil, err
+	}
+	return &a, tx.Commit()
+}
+
+func (s *SQLiteAccountStore) ListIdentities(ctx context.Context, accountID int64) ([]Identity, error) {
+	var ids []Identity
+	err := s.db.SelectContext(ctx, &ids,
+		"SELECT "+identityColumns+" FROM identities WHERE account_id = ? ORDER BY last_login_at DESC", accountID)
+	return ids, err
+}
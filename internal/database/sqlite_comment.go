@@ -0,0 +1,80 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type SQLiteCommentStore struct {
+	db *sqlx.DB
+}
+
+func NewSQLiteCommentStore(db *sqlx.DB) *SQLiteCommentStore {
+	return &SQLiteCommentStore{db: db}
+}
+
+func (s *SQLiteCommentStore) Create(ctx context.Context, c *Comment) error {
+	c.CreatedAt = time.Now().UTC()
+	if c.Status == "" {
+		c.Status = CommentPending
+	}
+
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO comments(post_id, parent_id, author_id, author_name, author_avatar, body, status, created_at)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?)`,
+		c.PostID, c.ParentID, c.AuthorID, c.AuthorName, c.AuthorAvatar, c.Body, c.Status, c.CreatedAt)
+	if err != nil {
+		return err
+	}
+	c.ID, err = res.LastInsertId()
+	return err
+}
+
+func (s *SQLiteCommentStore) GetByID(ctx context.Context, id int64) (*Comment, error) {
+	var c Comment
+	err := s.db.GetContext(ctx, &c, "SELECT "+commentColumns+" FROM comments c WHERE c.id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (s *SQLiteCommentStore) ListApproved(ctx context.Context, postID int64) ([]Comment, error) {
+	var comments []Comment
+	err := s.db.SelectContext(ctx, &comments,
+		"SELECT "+commentColumns+" FROM comments c WHERE c.post_id = ? AND c.status = 'approved' ORDER BY c.created_at, c.id",
+		postID)
+	return comments, err
+}
+
+func (s *SQLiteCommentStore) ListByStatus(ctx context.Context, status string, limit, offset int) ([]Comment, error) {
+	var comments []Comment
+	err := s.db.SelectContext(ctx, &comments, `
+		SELECT `+commentColumns+`, p.slug AS post_slug, p.title AS post_title
+		FROM comments c JOIN posts p ON p.id = c.post_id
+		WHERE c.status = ?
+		ORDER BY c.created_at DESC, c.id DESC LIMIT ? OFFSET ?`, status, limit, offset)
+	return comments, err
+}
+
+func (s *SQLiteCommentStore) CountByStatus(ctx context.Context, status string) (int, error) {
+	var n int
+	err := s.db.GetContext(ctx, &n, "SELECT count(*) FROM comments WHERE status = ?", status)
+	return n, err
+}
+
+func (s *SQLiteCommentStore) SetStatus(ctx context.Context, id int64, status string) error {
+	res, err := s.db.ExecContext(ctx, "UPDATE comments SET status = ? WHERE id = ?", status, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -44,6 +44,8 @@ func InitSqliteDB(db *sqlx.DB) {
 		fmt.Println("Inserted user ID:", id)
 	}
 
+	initSqlitePosts(db)
+
 	// // ---- READ (single) ----
 	// var fetched User
 	// err = db.Get(&fetched, `SELECT * FROM users WHERE id = ?`, id)
@@ -76,3 +78,160 @@ func InitSqliteDB(db *sqlx.DB) {
 	// }
 	// fmt.Println("Deleted user ID:", id)
 }
+
+func initSqlitePosts(db *sqlx.DB) {
+	schema := `
+	CREATE TABLE IF NOT EXISTS posts (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		slug TEXT NOT NULL UNIQUE,
+		title TEXT NOT NULL,
+		body TEXT NOT NULL,
+		published_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL,
+		author TEXT NOT NULL DEFAULT '',
+		status TEXT NOT NULL DEFAULT 'published',
+		search_text TEXT NOT NULL DEFAULT ''
+	);
+	CREATE INDEX IF NOT EXISTS posts_published_at_idx ON posts (published_at, id);
+	CREATE INDEX IF NOT EXISTS posts_status_idx ON posts (status, published_at);
+
+	CREATE TABLE IF NOT EXISTS tags (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL UNIQUE
+	);
+
+	CREATE TABLE IF NOT EXISTS post_tags (
+		post_id INTEGER NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+		tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+		PRIMARY KEY (post_id, tag_id)
+	);
+	CREATE INDEX IF NOT EXISTS post_tags_tag_id_idx ON post_tags (tag_id);
+
+	CREATE TABLE IF NOT EXISTS post_revisions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		post_id INTEGER NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+		title TEXT NOT NULL,
+		body TEXT NOT NULL,
+		author TEXT NOT NULL DEFAULT '',
+		created_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS post_revisions_post_id_idx ON post_revisions (post_id, id);
+
+	CREATE TABLE IF NOT EXISTS media (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		key TEXT NOT NULL UNIQUE,
+		content_type TEXT NOT NULL,
+		size INTEGER NOT NULL,
+		width INTEGER NOT NULL DEFAULT 0,
+		height INTEGER NOT NULL DEFAULT 0,
+		uploaded_by TEXT NOT NULL DEFAULT '',
+		created_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS media_variants (
+		media_id INTEGER NOT NULL REFERENCES media(id) ON DELETE CASCADE,
+		key TEXT NOT NULL UNIQUE,
+		width INTEGER NOT NULL,
+		height INTEGER NOT NULL,
+		PRIMARY KEY (media_id, width)
+	);
+
+	CREATE TABLE IF NOT EXISTS comments (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		post_id INTEGER NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+		parent_id INTEGER REFERENCES comments(id) ON DELETE CASCADE,
+		author_id TEXT NOT NULL,
+		author_name TEXT NOT NULL,
+		author_avatar TEXT NOT NULL DEFAULT '',
+		body TEXT NOT NULL,
+		status TEXT NOT NULL DEFAULT 'pending',
+		created_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS comments_post_id_idx ON comments (post_id, status, created_at);
+	CREATE INDEX IF NOT EXISTS comments_status_idx ON comments (status, created_at);
+
+	CREATE TABLE IF NOT EXISTS series (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		slug TEXT NOT NULL UNIQUE,
+		title TEXT NOT NULL,
+		created_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS series_posts (
+		series_id INTEGER NOT NULL REFERENCES series(id) ON DELETE CASCADE,
+		post_id INTEGER NOT NULL PRIMARY KEY REFERENCES posts(id) ON DELETE CASCADE,
+		position INTEGER NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS series_posts_series_id_idx ON series_posts (series_id, position);
+
+	CREATE TABLE IF NOT EXISTS related_posts (
+		post_id INTEGER NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+		related_id INTEGER NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+		score REAL NOT NULL,
+		PRIMARY KEY (post_id, related_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS accounts (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		email TEXT NOT NULL DEFAULT '',
+		name TEXT NOT NULL DEFAULT '',
+		first_name TEXT NOT NULL DEFAULT '',
+		last_name TEXT NOT NULL DEFAULT '',
+		avatar_url TEXT NOT NULL DEFAULT '',
+		created_at DATETIME NOT NULL,
+		last_login_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS identities (
+		provider TEXT NOT NULL,
+		provider_user_id TEXT NOT NULL,
+		account_id INTEGER NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+		email TEXT NOT NULL DEFAULT '',
+		created_at DATETIME NOT NULL,
+		last_login_at DATETIME NOT NULL,
+		PRIMARY KEY (provider, provider_user_id)
+	);
+	CREATE INDEX IF NOT EXISTS identities_account_id_idx ON identities (account_id);
+
+	CREATE TABLE IF NOT EXISTS sessions (
+		id TEXT PRIMARY KEY,
+		data BLOB NOT NULL,
+		account_id INTEGER REFERENCES accounts(id) ON DELETE CASCADE,
+		ip TEXT NOT NULL DEFAULT '',
+		user_agent TEXT NOT NULL DEFAULT '',
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL,
+		last_seen_at DATETIME NOT NULL,
+		expires_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS sessions_expires_at_idx ON sessions (expires_at);
+	CREATE INDEX IF NOT EXISTS sessions_account_id_idx ON sessions (account_id);`
+	db.MustExec(schema)
+
+	initSqliteSearch(db)
+}
+
+// initSqliteSearch creates the posts_fts full-text index of the title and
+// search_text of posts, along with the triggers that keep it in sync.
+func initSqliteSearch(db *sqlx.DB) {
+	schema := `
+	CREATE VIRTUAL TABLE IF NOT EXISTS posts_fts USING fts5(
+		title, search_text,
+		content='posts', content_rowid='id',
+		tokenize='porter unicode61'
+	);
+
+	CREATE TRIGGER IF NOT EXISTS posts_fts_insert AFTER INSERT ON posts BEGIN
+		INSERT INTO posts_fts(rowid, title, search_text) VALUES (new.id, new.title, new.search_text);
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS posts_fts_delete AFTER DELETE ON posts BEGIN
+		INSERT INTO posts_fts(posts_fts, rowid, title, search_text) VALUES ('delete', old.id, old.title, old.search_text);
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS posts_fts_update AFTER UPDATE OF title, search_text ON posts BEGIN
+		INSERT INTO posts_fts(posts_fts, rowid, title, search_text) VALUES ('delete', old.id, old.title, old.search_text);
+		INSERT INTO posts_fts(rowid, title, search_text) VALUES (new.id, new.title, new.search_text);
+	END;`
+	db.MustExec(schema)
+}
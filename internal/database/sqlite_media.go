@@ -0,0 +1,52 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type SQLiteMediaStore struct {
+	db *sqlx.DB
+}
+
+func NewSQLiteMediaStore(db *sqlx.DB) *SQLiteMediaStore {
+	return &SQLiteMediaStore{db: db}
+}
+
+func (s *SQLiteMediaStore) Create(ctx context.Context, m *Media) error {
+	m.CreatedAt = time.Now().UTC()
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		"INSERT INTO media(key, content_type, size, width, height, uploaded_by, created_at) VALUES(?, ?, ?, ?, ?, ?, ?)",
+		m.Key, m.ContentType, m.Size, m.Width, m.Height, m.UploadedBy, m.CreatedAt)
+	if err != nil {
+		return err
+	}
+	if m.ID, err = res.LastInsertId(); err != nil {
+		return err
+	}
+
+	for i := range m.Variants {
+		v := &m.Variants[i]
+		v.MediaID = m.ID
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO media_variants(media_id, key, width, height) VALUES(?, ?, ?, ?)",
+			v.MediaID, v.Key, v.Width, v.Height)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteMediaStore) ListByKeys(ctx context.Context, keys []string) ([]Media, error) {
+	return listMediaByKeys(ctx, s.db, keys)
+}
@@ -0,0 +1,266 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type SQLitePostStore struct {
+	db *sqlx.DB
+}
+
+func NewSQLitePostStore(db *sqlx.DB) *SQLitePostStore {
+	return &SQLitePostStore{db: db}
+}
+
+func (s *SQLitePostStore) GetBySlug(ctx context.Context, slug string) (*Post, error) {
+	var p Post
+	err := s.db.GetContext(ctx, &p, "SELECT "+postColumns+" FROM posts WHERE slug = ?", slug)
+	if err != nil {
+		return nil, err
+	}
+	return &p, s.loadTags(ctx, &p)
+}
+
+func (s *SQLitePostStore) GetByID(ctx context.Context, id int64) (*Post, error) {
+	var p Post
+	err := s.db.GetContext(ctx, &p, "SELECT "+postColumns+" FROM posts WHERE id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	return &p, s.loadTags(ctx, &p)
+}
+
+func (s *SQLitePostStore) ListAll(ctx context.Context, limit, offset int) ([]Post, error) {
+	return s.selectPosts(ctx, "SELECT "+postColumns+" FROM posts ORDER BY updated_at DESC, id DESC LIMIT ? OFFSET ?", limit, offset)
+}
+
+func (s *SQLitePostStore) CountAll(ctx context.Context) (int, error) {
+	var n int
+	err := s.db.GetContext(ctx, &n, "SELECT count(*) FROM posts")
+	return n, err
+}
+
+func (s *SQLitePostStore) List(ctx context.Context, limit, offset int) ([]Post, error) {
+	return s.selectPosts(ctx, "SELECT "+postColumns+" FROM posts WHERE status = 'published' ORDER BY published_at DESC, id DESC LIMIT ? OFFSET ?", limit, offset)
+}
+
+func (s *SQLitePostStore) Count(ctx context.Context) (int, error) {
+	var n int
+	err := s.db.GetContext(ctx, &n, "SELECT count(*) FROM posts WHERE status = 'published'")
+	return n, err
+}
+
+func (s *SQLitePostStore) ListIndex(ctx context.Context) ([]Post, error) {
+	return s.selectPosts(ctx, "SELECT id, slug, title, published_at, updated_at, author, status FROM posts WHERE status = 'published' ORDER BY published_at DESC, id DESC")
+}
+
+func (s *SQLitePostStore) ListTags(ctx context.Context) ([]TagCount, error) {
+	var tags []TagCount
+	err := s.db.SelectContext(ctx, &tags, `
+		SELECT t.name, count(*) AS count
+		FROM tags t
+		JOIN post_tags pt ON pt.tag_id = t.id
+		JOIN posts p ON p.id = pt.post_id
+		WHERE p.status = 'published'
+		GROUP BY t.id, t.name
+		ORDER BY t.name`)
+	return tags, err
+}
+
+func (s *SQLitePostStore) ListByTag(ctx context.Context, tag string, limit, offset int) ([]Post, error) {
+	return s.selectPosts(ctx, `
+		SELECT p.id, p.slug, p.title, p.body, p.published_at, p.updated_at, p.author, p.status
+		FROM posts p
+		JOIN post_tags pt ON pt.post_id = p.id
+		JOIN tags t ON t.id = pt.tag_id
+		WHERE t.name = ? AND p.status = 'published'
+		ORDER BY p.published_at DESC, p.id DESC LIMIT ? OFFSET ?`, tag, limit, offset)
+}
+
+func (s *SQLitePostStore) CountByTag(ctx context.Context, tag string) (int, error) {
+	var n int
+	err := s.db.GetContext(ctx, &n, `
+		SELECT count(*)
+		FROM post_tags pt
+		JOIN tags t ON t.id = pt.tag_id
+		JOIN posts p ON p.id = pt.post_id
+		WHERE t.name = ? AND p.status = 'published'`, tag)
+	return n, err
+}
+
+// ListMonths reads the year and month straight from the stored text, as
+// times are stored in UTC.
+func (s *SQLitePostStore) ListMonths(ctx context.Context) ([]MonthCount, error) {
+	var months []MonthCount
+	err := s.db.SelectContext(ctx, &months, `
+		SELECT CAST(substr(published_at, 1, 4) AS INTEGER) AS year,
+			CAST(substr(published_at, 6, 2) AS INTEGER) AS month,
+			count(*) AS count
+		FROM posts
+		WHERE status = 'published'
+		GROUP BY year, month
+		ORDER BY year DESC, month DESC`)
+	return months, err
+}
+
+func (s *SQLitePostStore) ListByDate(ctx context.Context, from, to time.Time, limit, offset int) ([]Post, error) {
+	return s.selectPosts(ctx,
+		"SELECT "+postColumns+" FROM posts WHERE status = 'published' AND published_at >= ? AND published_at < ? ORDER BY published_at DESC, id DESC LIMIT ? OFFSET ?",
+		from.UTC(), to.UTC(), limit, offset)
+}
+
+func (s *SQLitePostStore) CountByDate(ctx context.Context, from, to time.Time) (int, error) {
+	var n int
+	err := s.db.GetContext(ctx, &n,
+		"SELECT count(*) FROM posts WHERE status = 'published' AND published_at >= ? AND published_at < ?",
+		from.UTC(), to.UTC())
+	return n, err
+}
+
+func (s *SQLitePostStore) Create(ctx context.Context, p *Post, savedBy string) error {
+	prepareCreate(p)
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		"INSERT INTO posts(slug, title, body, published_at, updated_at, author, status, search_text) VALUES(?, ?, ?, ?, ?, ?, ?, ?)",
+		p.Slug, p.Title, p.Body, p.PublishedAt, p.UpdatedAt, p.Author, p.Status, p.SearchText)
+	if err != nil {
+		return err
+	}
+	if p.ID, err = res.LastInsertId(); err != nil {
+		return err
+	}
+
+	if err := s.setTags(ctx, tx, p.ID, p.Tags); err != nil {
+		return err
+	}
+	if err := s.insertRevision(ctx, tx, p, savedBy); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLitePostStore) Update(ctx context.Context, p *Post, savedBy string) error {
+	prepareUpdate(p)
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		"UPDATE posts SET slug = ?, title = ?, body = ?, published_at = ?, updated_at = ?, author = ?, status = ?, search_text = ? WHERE id = ?",
+		p.Slug, p.Title, p.Body, p.PublishedAt, p.UpdatedAt, p.Author, p.Status, p.SearchText, p.ID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	if err := s.setTags(ctx, tx, p.ID, p.Tags); err != nil {
+		return err
+	}
+	if err := s.insertRevision(ctx, tx, p, savedBy); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLitePostStore) Delete(ctx context.Context, id int64) error {
+	_, err := s.db.ExecContext(ctx, "DELETE FROM posts WHERE id = ?", id)
+	return err
+}
+
+func (s *SQLitePostStore) Adjacent(ctx context.Context, p *Post) (*Post, *Post, error) {
+	prev, err := s.getOne(ctx,
+		"SELECT "+postColumns+" FROM posts WHERE status = 'published' AND (published_at < ? OR (published_at = ? AND id < ?)) ORDER BY published_at DESC, id DESC LIMIT 1",
+		p.PublishedAt, p.PublishedAt, p.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	next, err := s.getOne(ctx,
+		"SELECT "+postColumns+" FROM posts WHERE status = 'published' AND (published_at > ? OR (published_at = ? AND id > ?)) ORDER BY published_at ASC, id ASC LIMIT 1",
+		p.PublishedAt, p.PublishedAt, p.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return prev, next, nil
+}
+
+func (s *SQLitePostStore) PublishDue(ctx context.Context, now time.Time) (int64, error) {
+	res, err := s.db.ExecContext(ctx,
+		"UPDATE posts SET status = 'published', updated_at = ? WHERE status = 'scheduled' AND published_at <= ?",
+		now.UTC(), now.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// setTags replaces the tags of the post with the given id.
+func (s *SQLitePostStore) setTags(ctx context.Context, tx *sqlx.Tx, postID int64, tags []string) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM post_tags WHERE post_id = ?", postID); err != nil {
+		return err
+	}
+
+	for _, tag := range tags {
+		if _, err := tx.ExecContext(ctx, "INSERT OR IGNORE INTO tags(name) VALUES(?)", tag); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx,
+			"INSERT OR IGNORE INTO post_tags(post_id, tag_id) SELECT ?, id FROM tags WHERE name = ?",
+			postID, tag)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SQLitePostStore) loadTags(ctx context.Context, p *Post) error {
+	posts := []Post{*p}
+	if err := loadTags(ctx, s.db, posts); err != nil {
+		return err
+	}
+	p.Tags = posts[0].Tags
+	return nil
+}
+
+// selectPosts runs a multi row query and loads the tags of the result.
+func (s *SQLitePostStore) selectPosts(ctx context.Context, query string, args ...any) ([]Post, error) {
+	var posts []Post
+	if err := s.db.SelectContext(ctx, &posts, query, args...); err != nil {
+		return nil, err
+	}
+	return posts, loadTags(ctx, s.db, posts)
+}
+
+// getOne runs a single row query and returns nil, without an error,
+// when nothing matched.
+func (s *SQLitePostStore) getOne(ctx context.Context, query string, args ...any) (*Post, error) {
+	var p Post
+	err := s.db.GetContext(ctx, &p, query, args...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, s.loadTags(ctx, &p)
+}
@@ -0,0 +1,44 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type SQLiteRelatedStore struct {
+	db *sqlx.DB
+}
+
+func NewSQLiteRelatedStore(db *sqlx.DB) *SQLiteRelatedStore {
+	return &SQLiteRelatedStore{db: db}
+}
+
+func (s *SQLiteRelatedStore) ListRelated(ctx context.Context, postID int64, limit int) ([]Post, error) {
+	posts := []Post{}
+	if err := s.db.SelectContext(ctx, &posts, listRelatedQuery, postID, limit); err != nil {
+		return nil, err
+	}
+	return posts, loadTags(ctx, s.db, posts)
+}
+
+func (s *SQLiteRelatedStore) ReplaceRelated(ctx context.Context, related []RelatedPost) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM related_posts"); err != nil {
+		return err
+	}
+	for _, rp := range related {
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO related_posts(post_id, related_id, score) VALUES(?, ?, ?)",
+			rp.PostID, rp.RelatedID, rp.Score)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,86 @@
+package database
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// SQLiteSearchStore searches the posts_fts FTS5 table, which triggers keep
+// in sync with posts.
+type SQLiteSearchStore struct {
+	db *sqlx.DB
+}
+
+func NewSQLiteSearchStore(db *sqlx.DB) *SQLiteSearchStore {
+	return &SQLiteSearchStore{db: db}
+}
+
+func (s *SQLiteSearchStore) Search(ctx context.Context, query string, limit, offset int) ([]SearchResult, error) {
+	match := ftsQuery(query)
+	if match == "" {
+		return []SearchResult{}, nil
+	}
+
+	var results []SearchResult
+	err := s.db.SelectContext(ctx, &results, `
+		SELECT p.id, p.slug, p.title, p.published_at, p.updated_at, p.author, p.status,
+			-bm25(posts_fts, 10.0, 1.0) AS rank,
+			snippet(posts_fts, 1, char(2), char(3), '…', 32) AS snippet
+		FROM posts_fts JOIN posts p ON p.id = posts_fts.rowid
+		WHERE posts_fts MATCH ? AND p.status = 'published'
+		ORDER BY rank DESC, p.published_at DESC
+		LIMIT ? OFFSET ?`, match, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return results, loadResultTags(ctx, s.db, results)
+}
+
+func (s *SQLiteSearchStore) CountSearch(ctx context.Context, query string) (int, error) {
+	match := ftsQuery(query)
+	if match == "" {
+		return 0, nil
+	}
+
+	var n int
+	err := s.db.GetContext(ctx, &n, `
+		SELECT count(*) FROM posts_fts JOIN posts p ON p.id = posts_fts.rowid
+		WHERE posts_fts MATCH ? AND p.status = 'published'`, match)
+	return n, err
+}
+
+func (s *SQLiteSearchStore) SetSearchText(ctx context.Context, postID int64, text string) error {
+	_, err := s.db.ExecContext(ctx, "UPDATE posts SET search_text = ? WHERE id = ?", text, postID)
+	return err
+}
+
+// ftsQuery turns a user query into an FTS5 query matching posts that
+// contain every term, the last one as a prefix so results show up while
+// typing.
+func ftsQuery(query string) string {
+	terms := searchTerms(query)
+	for i, t := range terms {
+		terms[i] = `"` + t + `"`
+	}
+	if len(terms) > 0 {
+		terms[len(terms)-1] += "*"
+	}
+	return strings.Join(terms, " ")
+}
+
+// loadResultTags fills in the Tags of search results.
+func loadResultTags(ctx context.Context, db *sqlx.DB, results []SearchResult) error {
+	posts := make([]Post, len(results))
+	for i := range results {
+		posts[i] = results[i].Post
+	}
+	if err := loadTags(ctx, db, posts); err != nil {
+		return err
+	}
+	for i := range results {
+		results[i].Tags = posts[i].Tags
+	}
+	return nil
+}
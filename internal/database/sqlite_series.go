@@ -0,0 +1,110 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type SQLiteSeriesStore struct {
+	db *sqlx.DB
+}
+
+func NewSQLiteSeriesStore(db *sqlx.DB) *SQLiteSeriesStore {
+	return &SQLiteSeriesStore{db: db}
+}
+
+func (s *SQLiteSeriesStore) GetBySlug(ctx context.Context, slug string) (*Series, error) {
+	var series Series
+	err := s.db.GetContext(ctx, &series, "SELECT id, slug, title FROM series WHERE slug = ?", slug)
+	if err != nil {
+		return nil, err
+	}
+	return &series, loadSeriesPosts(ctx, s.db, &series)
+}
+
+func (s *SQLiteSeriesStore) ForPost(ctx context.Context, postID int64) (*Series, error) {
+	var series Series
+	err := s.db.GetContext(ctx, &series, `
+		SELECT s.id, s.slug, s.title, sp.position
+		FROM series s JOIN series_posts sp ON sp.series_id = s.id
+		WHERE sp.post_id = ?`, postID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &series, loadSeriesPosts(ctx, s.db, &series)
+}
+
+func (s *SQLiteSeriesStore) List(ctx context.Context) ([]Series, error) {
+	var rows []struct {
+		Series
+		// SQLite hands max() of a DATETIME column back as text.
+		UpdatedAt string `db:"updated_at"`
+	}
+	if err := s.db.SelectContext(ctx, &rows, listSeriesQuery); err != nil {
+		return nil, err
+	}
+
+	series := make([]Series, len(rows))
+	for i, row := range rows {
+		series[i] = row.Series
+		t, err := time.Parse("2006-01-02 15:04:05.999999999-07:00", row.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		series[i].UpdatedAt = t.UTC()
+	}
+	return series, nil
+}
+
+func (s *SQLiteSeriesStore) SetPostSeries(ctx context.Context, postID int64, slug, title string, position int) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM series_posts WHERE post_id = ?", postID); err != nil {
+		return err
+	}
+
+	if slug != "" {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO series(slug, title, created_at) VALUES(?, ?, ?)
+			ON CONFLICT(slug) DO UPDATE SET title = excluded.title`,
+			slug, title, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+
+		var seriesID int64
+		if err := tx.GetContext(ctx, &seriesID, "SELECT id FROM series WHERE slug = ?", slug); err != nil {
+			return err
+		}
+		if position <= 0 {
+			err := tx.GetContext(ctx, &position,
+				"SELECT coalesce(max(position), 0) + 1 FROM series_posts WHERE series_id = ?", seriesID)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO series_posts(series_id, post_id, position) VALUES(?, ?, ?)",
+			seriesID, postID, position)
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM series WHERE id NOT IN (SELECT series_id FROM series_posts)"); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,83 @@
+package database
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type TagCount struct {
+	Name  string `db:"name" json:"name"`
+	Count int    `db:"count" json:"count"`
+}
+
+// URL returns the public path of the tag page.
+func (t *TagCount) URL() string {
+	return TagURL(t.Name)
+}
+
+// TagURL returns the public path of the page listing the posts tagged with
+// name, escaping it so tags like "c#" or "a/b" stay a single segment.
+func TagURL(name string) string {
+	return "/tags/" + url.PathEscape(name)
+}
+
+// CleanTags trims and lower cases tags, dropping empty ones and duplicates.
+func CleanTags(tags []string) []string {
+	seen := map[string]bool{}
+	cleaned := make([]string, 0, len(tags))
+	for _, t := range tags {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		cleaned = append(cleaned, t)
+	}
+	return cleaned
+}
+
+// tagBatchSize keeps the IN lists of loadTags under the bind parameter
+// limits of both databases.
+const tagBatchSize = 500
+
+// loadTags fills in the Tags of posts from the post_tags table.
+func loadTags(ctx context.Context, db *sqlx.DB, posts []Post) error {
+	byID := make(map[int64]*Post, len(posts))
+	ids := make([]int64, len(posts))
+	for i := range posts {
+		ids[i] = posts[i].ID
+		byID[posts[i].ID] = &posts[i]
+		posts[i].Tags = []string{}
+	}
+
+	for len(ids) > 0 {
+		batch := ids[:min(len(ids), tagBatchSize)]
+		ids = ids[len(batch):]
+
+		query, args, err := sqlx.In(`
+			SELECT pt.post_id, t.name
+			FROM post_tags pt JOIN tags t ON t.id = pt.tag_id
+			WHERE pt.post_id IN (?)
+			ORDER BY t.name`, batch)
+		if err != nil {
+			return err
+		}
+
+		var rows []struct {
+			PostID int64  `db:"post_id"`
+			Name   string `db:"name"`
+		}
+		if err := db.SelectContext(ctx, &rows, db.Rebind(query), args...); err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			p := byID[row.PostID]
+			p.Tags = append(p.Tags, row.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package database
+
+import "testing"
+
+func TestTagURL(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"go", "/tags/go"},
+		{"c#", "/tags/c%23"},
+		{"a/b", "/tags/a%2Fb"},
+		{"q?", "/tags/q%3F"},
+		{"two words", "/tags/two%20words"},
+	}
+	for _, tt := range tests {
+		if got := TagURL(tt.name); got != tt.want {
+			t.Errorf("TagURL(%q) = %q; want %q", tt.name, got, tt.want)
+		}
+	}
+}
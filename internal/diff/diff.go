@@ -0,0 +1,156 @@
+// Package diff computes line based differences between two texts.
+package diff
+
+import "strings"
+
+// Op is the kind of change a Line stands for.
+type Op int
+
+const (
+	Equal Op = iota
+	Insert
+	Delete
+)
+
+// Line is one line of a diff. OldNum and NewNum are the 1-based line
+// numbers in each text, 0 when the line is not part of that text.
+type Line struct {
+	Op     Op
+	Text   string
+	OldNum int
+	NewNum int
+}
+
+func (l Line) IsInsert() bool { return l.Op == Insert }
+func (l Line) IsDelete() bool { return l.Op == Delete }
+
+// Lines returns the line diff turning a into b, using the linear space
+// variant of the Myers algorithm.
+func Lines(a, b string) []Line {
+	d := &differ{a: splitLines(a), b: splitLines(b)}
+	d.compare(0, len(d.a), 0, len(d.b))
+	return d.out
+}
+
+// Changed reports whether lines contain anything but Equal lines.
+func Changed(lines []Line) bool {
+	for _, l := range lines {
+		if l.Op != Equal {
+			return true
+		}
+	}
+	return false
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(strings.ReplaceAll(s, "\r\n", "\n"), "\n"), "\n")
+}
+
+// differ collects the lines of the diff of a and b, in order.
+type differ struct {
+	a, b []string
+	out  []Line
+}
+
+// compare appends the diff of a[aLo:aHi] and b[bLo:bHi], splitting it at
+// a middle snake until only insertions or deletions are left.
+func (d *differ) compare(aLo, aHi, bLo, bHi int) {
+	// Common prefix and suffix don't need the full algorithm.
+	pre := 0
+	for aLo+pre < aHi && bLo+pre < bHi && d.a[aLo+pre] == d.b[bLo+pre] {
+		pre++
+	}
+	d.equal(aLo, bLo, pre)
+	aLo, bLo = aLo+pre, bLo+pre
+	suf := 0
+	for aLo < aHi-suf && bLo < bHi-suf && d.a[aHi-1-suf] == d.b[bHi-1-suf] {
+		suf++
+	}
+	aHi, bHi = aHi-suf, bHi-suf
+
+	switch {
+	case aLo == aHi:
+		for j := bLo; j < bHi; j++ {
+			d.out = append(d.out, Line{Op: Insert, Text: d.b[j], NewNum: j + 1})
+		}
+	case bLo == bHi:
+		for i := aLo; i < aHi; i++ {
+			d.out = append(d.out, Line{Op: Delete, Text: d.a[i], OldNum: i + 1})
+		}
+	default:
+		x, y, u, v := d.middleSnake(aLo, aHi, bLo, bHi)
+		d.compare(aLo, x, bLo, y)
+		d.equal(x, y, u-x)
+		d.compare(u, aHi, v, bHi)
+	}
+	d.equal(aHi, bHi, suf)
+}
+
+// equal appends the n lines common to a from i and b from j.
+func (d *differ) equal(i, j, n int) {
+	for k := 0; k < n; k++ {
+		d.out = append(d.out, Line{Op: Equal, Text: d.a[i+k], OldNum: i + k + 1, NewNum: j + k + 1})
+	}
+}
+
+// middleSnake returns the snake, from (x, y) to (u, v), in the middle of a
+// shortest edit script from a[aLo:aHi] to b[bLo:bHi]. It runs the Myers
+// search from both ends until the paths meet, keeping only the furthest
+// reaching path of each diagonal, so memory stays linear in the input.
+// Both ranges must be non-empty and differ in their first and last line.
+func (d *differ) middleSnake(aLo, aHi, bLo, bHi int) (x, y, u, v int) {
+	n, m := aHi-aLo, bHi-bLo
+	delta := n - m
+	odd := delta%2 != 0
+	max := (n + m + 1) / 2
+
+	// vf[offset+k] is how far along a the forward path on diagonal k got,
+	// vb[offset+k] the same from the ends, on reversed diagonals.
+	offset := max + 1
+	vf := make([]int, 2*max+3)
+	vb := make([]int, 2*max+3)
+
+	for e := 0; e <= max; e++ {
+		for k := -e; k <= e; k += 2 {
+			var i int
+			if k == -e || (k != e && vf[offset+k-1] < vf[offset+k+1]) {
+				i = vf[offset+k+1]
+			} else {
+				i = vf[offset+k-1] + 1
+			}
+			j := i - k
+			i0, j0 := i, j
+			for i < n && j < m && d.a[aLo+i] == d.b[bLo+j] {
+				i++
+				j++
+			}
+			vf[offset+k] = i
+			// Reverse diagonal delta-k reached n-vb[...] after e-1 steps.
+			if r := delta - k; odd && r >= -(e-1) && r <= e-1 && i+vb[offset+r] >= n {
+				return aLo + i0, bLo + j0, aLo + i, bLo + j
+			}
+		}
+		for k := -e; k <= e; k += 2 {
+			var i int
+			if k == -e || (k != e && vb[offset+k-1] < vb[offset+k+1]) {
+				i = vb[offset+k+1]
+			} else {
+				i = vb[offset+k-1] + 1
+			}
+			j := i - k
+			i0, j0 := i, j
+			for i < n && j < m && d.a[aHi-1-i] == d.b[bHi-1-j] {
+				i++
+				j++
+			}
+			vb[offset+k] = i
+			if r := delta - k; !odd && r >= -e && r <= e && i+vf[offset+r] >= n {
+				return aLo + n - i, bLo + m - j, aLo + n - i0, bLo + m - j0
+			}
+		}
+	}
+	panic("diff: no middle snake")
+}
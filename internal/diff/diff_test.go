@@ -0,0 +1,129 @@
+package diff
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// format writes lines as " text", "+text" and "-text" with their numbers.
+func format(lines []Line) []string {
+	out := make([]string, len(lines))
+	for i, l := range lines {
+		op := " "
+		switch l.Op {
+		case Insert:
+			op = "+"
+		case Delete:
+			op = "-"
+		}
+		out[i] = fmt.Sprintf("%s%d,%d %s", op, l.OldNum, l.NewNum, l.Text)
+	}
+	return out
+}
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    string
+		want    []string
+		changed bool
+	}{
+		{"both empty", "", "", nil, false},
+		{"from empty", "", "a\nb\n", []string{"+0,1 a", "+0,2 b"}, true},
+		{"to empty", "a\nb\n", "", []string{"-1,0 a", "-2,0 b"}, true},
+		{"same", "a\nb\n", "a\nb\n", []string{" 1,1 a", " 2,2 b"}, false},
+		{"insert only", "a\nc\n", "a\nb\nc\n", []string{" 1,1 a", "+0,2 b", " 2,3 c"}, true},
+		{"delete only", "a\nb\nc\n", "a\nc\n", []string{" 1,1 a", "-2,0 b", " 3,2 c"}, true},
+		{"full rewrite", "a\nb\n", "c\nd\n", []string{"-1,0 a", "-2,0 b", "+0,1 c", "+0,2 d"}, true},
+		{"replace middle", "a\nb\nc\n", "a\nx\nc\n", []string{" 1,1 a", "-2,0 b", "+0,2 x", " 3,3 c"}, true},
+		{"trailing newline", "a\nb", "a\nb\n", []string{" 1,1 a", " 2,2 b"}, false},
+		{"crlf", "a\r\nb\r\n", "a\nb\n", []string{" 1,1 a", " 2,2 b"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := Lines(tt.a, tt.b)
+			if got := format(lines); strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("Lines(%q, %q) =\n%s\nwant\n%s", tt.a, tt.b, strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+			if got := Changed(lines); got != tt.changed {
+				t.Errorf("Changed = %v, want %v", got, tt.changed)
+			}
+		})
+	}
+}
+
+// lcs returns the length of the longest common subsequence of x and y.
+func lcs(x, y []string) int {
+	prev := make([]int, len(y)+1)
+	cur := make([]int, len(y)+1)
+	for i := range x {
+		for j := range y {
+			if x[i] == y[j] {
+				cur[j+1] = prev[j] + 1
+			} else {
+				cur[j+1] = max(cur[j], prev[j+1])
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(y)]
+}
+
+// TestLinesShortest checks on random texts that the diff turns a into b
+// with as few insertions and deletions as possible.
+func TestLinesShortest(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	random := func() []string {
+		lines := make([]string, rng.Intn(30))
+		for i := range lines {
+			lines[i] = string(rune('a' + rng.Intn(4)))
+		}
+		return lines
+	}
+
+	for n := 0; n < 500; n++ {
+		x, y := random(), random()
+		a, b := strings.Join(x, "\n"), strings.Join(y, "\n")
+		lines := Lines(a, b)
+
+		var old, new []string
+		edits := 0
+		for _, l := range lines {
+			if l.Op != Insert {
+				if l.OldNum != len(old)+1 {
+					t.Fatalf("Lines(%q, %q): old line %d numbered %d", a, b, len(old)+1, l.OldNum)
+				}
+				old = append(old, l.Text)
+			}
+			if l.Op != Delete {
+				if l.NewNum != len(new)+1 {
+					t.Fatalf("Lines(%q, %q): new line %d numbered %d", a, b, len(new)+1, l.NewNum)
+				}
+				new = append(new, l.Text)
+			}
+			if l.Op != Equal {
+				edits++
+			}
+		}
+		if strings.Join(old, "\n") != a || strings.Join(new, "\n") != b {
+			t.Fatalf("Lines(%q, %q) doesn't rebuild the texts", a, b)
+		}
+		if want := len(x) + len(y) - 2*lcs(x, y); edits != want {
+			t.Fatalf("Lines(%q, %q) has %d edits, want %d", a, b, edits, want)
+		}
+	}
+}
+
+func TestLinesLargeRewrite(t *testing.T) {
+	var a, b strings.Builder
+	for i := 0; i < 3000; i++ {
+		fmt.Fprintf(&a, "old %d\n", i)
+		fmt.Fprintf(&b, "new %d\n", i)
+	}
+	lines := Lines(a.String(), b.String())
+	if len(lines) != 6000 {
+		t.Fatalf("got %d lines, want 6000", len(lines))
+	}
+}
@@ -0,0 +1,335 @@
+// Package export renders the public pages of the site to a directory, for
+// plain static hosting.
+package export
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gochi-demo/internal/config"
+	"github.com/gochi-demo/internal/web"
+)
+
+// seeds are the routes the export starts from. Every other page is found
+// by following their links and the sitemap.
+var seeds = []string{"/", "/tags", "/archive", "/rss.xml", "/atom.xml", "/feed.json", "/sitemap.xml", "/robots.txt"}
+
+// exportable matches the paths of the public routes and static assets.
+// Search, comments, sign in and the admin need the server and are left
+// out.
+var exportable = []*regexp.Regexp{
+	regexp.MustCompile(`^/$`),
+	regexp.MustCompile(`^/posts/[^/]+(/card\.png)?$`),
+	regexp.MustCompile(`^/tags(/[^/]+(/feed\.xml)?)?$`),
+	regexp.MustCompile(`^/archive$`),
+	regexp.MustCompile(`^/[0-9]{4}(/[0-9]{2})?$`),
+	regexp.MustCompile(`^/series/[^/]+$`),
+	regexp.MustCompile(`^/media/.+$`),
+	regexp.MustCompile(`^/static/.+$`),
+	regexp.MustCompile(`^/(rss\.xml|atom\.xml|feed\.json|robots\.txt|sitemap(-[0-9]+)?\.xml)$`),
+}
+
+var (
+	linkAttr   = regexp.MustCompile(`(\s(?:href|src)=")([^"]*)(")`)
+	srcsetAttr = regexp.MustCompile(`(\ssrcset=")([^"]*)(")`)
+	sitemapLoc = regexp.MustCompile(`<loc>([^<]+)</loc>`)
+	tagPage    = regexp.MustCompile(`^/tags/[^/?]+$`)
+	postPage   = regexp.MustCompile(`^/posts/[^/?]+$`)
+)
+
+// Exporter renders pages through handler, the router of the live site, so
+// exported files hold what the server would answer.
+type Exporter struct {
+	handler http.Handler
+}
+
+func New(handler http.Handler) *Exporter {
+	return &Exporter{handler: handler}
+}
+
+// page is a fetched route waiting to be written.
+type page struct {
+	file string
+	body []byte
+	html bool
+}
+
+// Run exports the site to dir. Every file is the body the server returns
+// for its route, except that links between exported HTML pages are made
+// relative so the result works from any directory, file:// included.
+func (e *Exporter) Run(ctx context.Context, dir string) (*Report, error) {
+	report := &Report{Dir: dir}
+
+	// files maps the exported routes, as linkKey returns them, to their
+	// file in dir.
+	files := map[string]string{}
+	if err := e.copyStatic(dir, files, report); err != nil {
+		return nil, err
+	}
+
+	// Pages go through a real HTTP server, which sniffs content types
+	// and buffers responses just like the live one.
+	srv := httptest.NewServer(e.handler)
+	defer srv.Close()
+	client := srv.Client()
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	var pages []*page
+	queue := append([]string(nil), seeds...)
+	queued := map[string]bool{}
+	for _, s := range seeds {
+		queued[s] = true
+	}
+
+	for len(queue) > 0 {
+		key := queue[0]
+		queue = queue[1:]
+
+		status, contentType, body, err := get(ctx, client, srv.URL+key)
+		if err != nil {
+			return nil, err
+		}
+		if status != http.StatusOK {
+			report.Skipped = append(report.Skipped, fmt.Sprintf("%s (%d)", key, status))
+			continue
+		}
+
+		p := &page{body: body, html: strings.HasPrefix(contentType, "text/html")}
+		p.file = fileName(key, p.html)
+		files[key] = p.file
+		pages = append(pages, p)
+
+		found := links(p)
+		if tagPage.MatchString(key) {
+			// Nothing links to the tag feeds.
+			found = append(found, key+"/feed.xml")
+		}
+		if postPage.MatchString(key) {
+			// Social cards are only named in meta tags, by absolute URL.
+			found = append(found, key+"/card.png")
+		}
+		for _, link := range found {
+			// Static assets are already copied.
+			if k, ok := linkKey(link); ok && !queued[k] && files[k] == "" {
+				queued[k] = true
+				queue = append(queue, k)
+			}
+		}
+	}
+
+	for _, p := range pages {
+		body := p.body
+		if p.html {
+			body = relativize(body, p.file, files)
+			report.Pages++
+		} else {
+			report.Files++
+		}
+		if err := writeFile(filepath.Join(dir, filepath.FromSlash(p.file)), body); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Strings(report.Skipped)
+	return report, nil
+}
+
+// get fetches url and returns the response status, content type and body.
+func get(ctx context.Context, client *http.Client, url string) (int, string, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, "", nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, "", nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	return resp.StatusCode, resp.Header.Get("Content-Type"), body, err
+}
+
+// copyStatic copies the static assets of web.FS, served at /static/, to
+// dir and records them in files.
+func (e *Exporter) copyStatic(dir string, files map[string]string, report *Report) error {
+	return fs.WalkDir(web.FS, "static", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := fs.ReadFile(web.FS, name)
+		if err != nil {
+			return err
+		}
+		files["/"+name] = name
+		report.Static++
+		return writeFile(filepath.Join(dir, filepath.FromSlash(name)), data)
+	})
+}
+
+// links returns the URLs p links to: the href, src and srcset attributes
+// of HTML pages and the locations of sitemaps.
+func links(p *page) []string {
+	var urls []string
+	if p.html {
+		for _, m := range linkAttr.FindAllSubmatch(p.body, -1) {
+			urls = append(urls, html.UnescapeString(string(m[2])))
+		}
+		for _, m := range srcsetAttr.FindAllSubmatch(p.body, -1) {
+			urls = append(urls, srcsetURLs(html.UnescapeString(string(m[2])))...)
+		}
+		return urls
+	}
+
+	site := config.GetSite().URL
+	for _, m := range sitemapLoc.FindAllSubmatch(p.body, -1) {
+		if loc := html.UnescapeString(string(m[1])); strings.HasPrefix(loc, site+"/") {
+			urls = append(urls, strings.TrimPrefix(loc, site))
+		}
+	}
+	return urls
+}
+
+// srcsetURLs returns the URLs of a srcset attribute value.
+func srcsetURLs(srcset string) []string {
+	var urls []string
+	for _, candidate := range strings.Split(srcset, ",") {
+		if fields := strings.Fields(candidate); len(fields) > 0 {
+			urls = append(urls, fields[0])
+		}
+	}
+	return urls
+}
+
+// linkKey returns the route a link of the site points at, escaped and
+// without its fragment, and whether it is one of the exported routes.
+// Only the ?page= query param is kept, as no other one is exported.
+func linkKey(link string) (string, bool) {
+	u, err := url.Parse(link)
+	if err != nil || u.Scheme != "" || u.Host != "" || !strings.HasPrefix(u.Path, "/") {
+		return "", false
+	}
+	// Tags may be named "..", which would end up outside of their
+	// directory once written.
+	for _, seg := range strings.Split(u.Path, "/") {
+		if seg == "." || seg == ".." {
+			return "", false
+		}
+	}
+
+	p := u.EscapedPath()
+	public := false
+	for _, re := range exportable {
+		public = public || re.MatchString(p)
+	}
+	if !public {
+		return "", false
+	}
+
+	q := u.Query()
+	pageNum := q.Get("page")
+	if len(q) > 1 || (len(q) == 1 && pageNum == "") {
+		return "", false
+	}
+	if pageNum != "" {
+		return p + "?page=" + pageNum, true
+	}
+	return p, true
+}
+
+// fileName returns the file, relative to the export directory, holding
+// the route key, unescaped as static hosts look it up. HTML pages are the
+// index.html of a directory named after their route, so the URLs of the
+// sitemap and feeds work as they are. Later pages of a listing go under
+// page/, so /tags/go?page=2 becomes tags/go/page/2/index.html.
+func fileName(key string, isHTML bool) string {
+	p, pageNum, _ := strings.Cut(key, "?page=")
+	if unescaped, err := url.PathUnescape(p); err == nil {
+		p = unescaped
+	}
+	name := strings.TrimPrefix(p, "/")
+	if !isHTML {
+		return name
+	}
+	if pageNum != "" {
+		name = path.Join(name, "page", pageNum)
+	}
+	return path.Join(name, "index.html")
+}
+
+// relativize points the links of the HTML page stored in file at the
+// exported files, relative to it. Other links are left untouched.
+func relativize(body []byte, file string, files map[string]string) []byte {
+	rewrite := func(link string) (string, bool) {
+		key, ok := linkKey(link)
+		if !ok || files[key] == "" {
+			return link, false
+		}
+		rel, err := filepath.Rel(filepath.FromSlash(path.Dir(file)), filepath.FromSlash(files[key]))
+		if err != nil {
+			return link, false
+		}
+		// File names are unescaped, links to them can't be.
+		segs := strings.Split(filepath.ToSlash(rel), "/")
+		for i, seg := range segs {
+			segs[i] = url.PathEscape(seg)
+		}
+		rel = strings.Join(segs, "/")
+		if i := strings.IndexByte(link, '#'); i >= 0 {
+			rel += link[i:]
+		}
+		return rel, true
+	}
+
+	body = linkAttr.ReplaceAllFunc(body, func(m []byte) []byte {
+		parts := linkAttr.FindSubmatch(m)
+		link, ok := rewrite(html.UnescapeString(string(parts[2])))
+		if !ok {
+			return m
+		}
+		return []byte(string(parts[1]) + html.EscapeString(link) + string(parts[3]))
+	})
+	return srcsetAttr.ReplaceAllFunc(body, func(m []byte) []byte {
+		parts := srcsetAttr.FindSubmatch(m)
+		candidates := strings.Split(html.UnescapeString(string(parts[2])), ",")
+		changed := false
+		for i, c := range candidates {
+			fields := strings.Fields(c)
+			if len(fields) == 0 {
+				continue
+			}
+			link, ok := rewrite(fields[0])
+			if !ok {
+				continue
+			}
+			fields[0] = link
+			candidates[i] = strings.Join(fields, " ")
+			changed = true
+		}
+		if !changed {
+			return m
+		}
+		return []byte(string(parts[1]) + html.EscapeString(strings.Join(candidates, ", ")) + string(parts[3]))
+	})
+}
+
+func writeFile(name string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(name, data, 0o644)
+}
@@ -0,0 +1,87 @@
+package export
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileName(t *testing.T) {
+	tests := []struct {
+		key    string
+		isHTML bool
+		want   string
+	}{
+		{"/", true, "index.html"},
+		{"/posts/hello", true, "posts/hello/index.html"},
+		{"/tags/go?page=2", true, "tags/go/page/2/index.html"},
+		{"/?page=3", true, "page/3/index.html"},
+		{"/tags/c%23", true, "tags/c#/index.html"},
+		{"/tags/go/feed.xml", false, "tags/go/feed.xml"},
+		{"/sitemap.xml", false, "sitemap.xml"},
+	}
+	for _, tt := range tests {
+		if got := fileName(tt.key, tt.isHTML); got != tt.want {
+			t.Errorf("fileName(%q) = %q; want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestLinkKey(t *testing.T) {
+	tests := []struct {
+		link string
+		want string
+		ok   bool
+	}{
+		{"/posts/hello#comments", "/posts/hello", true},
+		{"/tags/a%2Fb", "/tags/a%2Fb", true},
+		{"/tags/c%23/feed.xml", "/tags/c%23/feed.xml", true},
+		{"/tags/go?page=2", "/tags/go?page=2", true},
+		{"/tags/..", "", false},
+		{"/search?q=go", "", false},
+		{"/admin/posts", "", false},
+		{"https://example.com/posts/hello", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := linkKey(tt.link)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("linkKey(%q) = %q, %v; want %q, %v", tt.link, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRunExportsSocialCards(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\ncard")
+	pages := map[string]string{
+		"/":            `<a href="/posts/hello">Hello</a>`,
+		"/posts/hello": `<meta property="og:image" content="http://localhost:10000/posts/hello/card.png?v=1">`,
+	}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/posts/hello/card.png" {
+			w.Header().Set("Content-Type", "image/png")
+			w.Write(png)
+			return
+		}
+		body, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte("<!DOCTYPE html><html><body>" + body + "</body></html>"))
+	})
+
+	dir := t.TempDir()
+	if _, err := New(handler).Run(context.Background(), dir); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(filepath.Join(dir, "posts", "hello", "card.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(png) {
+		t.Errorf("card.png = %q; want %q", got, png)
+	}
+}
@@ -0,0 +1,33 @@
+package export
+
+import (
+	"fmt"
+	"io"
+)
+
+// Report sums up an export.
+type Report struct {
+	Dir string
+	// Pages counts the HTML pages, Files the feeds, sitemaps and media,
+	// and Static the assets copied from web.FS.
+	Pages  int
+	Files  int
+	Static int
+	// Skipped lists the linked routes that didn't answer 200, with their
+	// status.
+	Skipped []string
+}
+
+// Write prints the totals followed by the skipped routes.
+func (r *Report) Write(w io.Writer) {
+	fmt.Fprintf(w, "Exported the site to %s.\n", r.Dir)
+	fmt.Fprintf(w, "  %-7s  %d\n", "pages", r.Pages)
+	fmt.Fprintf(w, "  %-7s  %d\n", "files", r.Files)
+	fmt.Fprintf(w, "  %-7s  %d\n", "static", r.Static)
+	if len(r.Skipped) > 0 {
+		fmt.Fprintf(w, "Skipped %d route(s):\n", len(r.Skipped))
+		for _, s := range r.Skipped {
+			fmt.Fprintln(w, "  "+s)
+		}
+	}
+}
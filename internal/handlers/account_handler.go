@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gochi-demo/internal/app"
+	"github.com/gochi-demo/internal/auth"
+	"github.com/gochi-demo/internal/database"
+	"github.com/gochi-demo/internal/web"
+)
+
+// AccountHandler serves the /account pages of the signed in user. Its
+// routes are meant to sit behind auth.RequireAuth.
+type AccountHandler struct {
+	app       *app.App
+	templates *web.Templates
+}
+
+func NewAccountHandler(app *app.App, templates *web.Templates) *AccountHandler {
+	return &AccountHandler{app: app, templates: templates}
+}
+
+// Routes mounts the account pages on r.
+func (h *AccountHandler) Routes(r chi.Router) {
+	r.Get("/sessions", h.ListSessions)
+	r.Post("/sessions/revoke", h.RevokeOtherSessions)
+	r.Post("/sessions/{session}/revoke", h.RevokeSession)
+}
+
+// sessionRow is a row of a session list.
+type sessionRow struct {
+	database.Session
+	Device  string
+	Current bool
+}
+
+// sessionRows prepares sessions for listing, flagging the one of r.
+func sessionRows(r *http.Request, sessions []database.Session) []sessionRow {
+	current := auth.CurrentSessionID(r)
+	rows := make([]sessionRow, len(sessions))
+	for i, s := range sessions {
+		rows[i] = sessionRow{Session: s, Device: describeAgent(s.UserAgent), Current: s.ID == current}
+	}
+	return rows
+}
+
+// findSession returns the session of sessions with the given handle.
+func findSession(sessions []database.Session, handle string) *database.Session {
+	for i := range sessions {
+		if sessions[i].Handle() == handle {
+			return &sessions[i]
+		}
+	}
+	return nil
+}
+
+// browserNames and systemNames are recognized in user agents, most
+// specific first, as Edge and Chrome user agents also name Safari.
+var (
+	browserNames = [][2]string{
+		{"Edg/", "Edge"}, {"OPR/", "Opera"}, {"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"}, {"Safari/", "Safari"}, {"curl/", "curl"},
+	}
+	systemNames = [][2]string{
+		{"Android", "Android"}, {"iPhone", "iOS"}, {"iPad", "iPadOS"},
+		{"Windows", "Windows"}, {"Mac OS X", "macOS"}, {"Linux", "Linux"},
+	}
+)
+
+// describeAgent turns a user agent into a short "Browser on System" label.
+func describeAgent(ua string) string {
+	find := func(names [][2]string) string {
+		for _, n := range names {
+			if strings.Contains(ua, n[0]) {
+				return n[1]
+			}
+		}
+		return ""
+	}
+
+	browser, system := find(browserNames), find(systemNames)
+	switch {
+	case browser != "" && system != "":
+		return browser + " on " + system
+	case browser != "":
+		return browser
+	case system != "":
+		return system
+	case ua != "":
+		return ua
+	}
+	return "Unknown device"
+}
+
+func (h *AccountHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	user, err := auth.GetUserFromContext(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	sessions, err := h.app.Sessions.ListAccountSessions(r.Context(), user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data := map[string]any{
+		"Username":   username(r),
+		"Sessions":   sessionRows(r, sessions),
+		"RevokeBase": "/account/sessions",
+	}
+
+	err = h.templates.ExecuteTemplate(w, "account_sessions.html", data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// RevokeSession signs the {session} session of the user out. Revoking the
+// current session signs out like /logout.
+func (h *AccountHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	user, err := auth.GetUserFromContext(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	sessions, err := h.app.Sessions.ListAccountSessions(r.Context(), user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	session := findSession(sessions, chi.URLParam(r, "session"))
+	if session == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if session.ID == auth.CurrentSessionID(r) {
+		if err := auth.ClearUserSession(w, r); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+
+	if err := h.app.Sessions.DeleteSession(r.Context(), session.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/account/sessions", http.StatusSeeOther)
+}
+
+// RevokeOtherSessions signs the user out everywhere but in the current
+// session.
+func (h *AccountHandler) RevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	user, err := auth.GetUserFromContext(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	sessions, err := h.app.Sessions.ListAccountSessions(r.Context(), user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	current := auth.CurrentSessionID(r)
+	for _, s := range sessions {
+		if s.ID == current {
+			continue
+		}
+		if err := h.app.Sessions.DeleteSession(r.Context(), s.ID); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	http.Redirect(w, r, "/account/sessions", http.StatusSeeOther)
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gochi-demo/internal/database"
+)
+
+// ListAccounts lists the accounts of the people who signed in, most
+// recently seen first.
+func (h *AdminHandler) ListAccounts(w http.ResponseWriter, r *http.Request) {
+	accounts, err := h.app.Accounts.List(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data := map[string]any{
+		"Username": username(r),
+		"Accounts": accounts,
+	}
+
+	err = h.templates.ExecuteTemplate(w, "admin_accounts.html", data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// getAccount loads the account named by the {id} URL param. It writes the
+// error response itself and returns nil when there is none.
+func (h *AdminHandler) getAccount(w http.ResponseWriter, r *http.Request) *database.Account {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return nil
+	}
+
+	account, err := h.app.Accounts.GetByID(r.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.NotFound(w, r)
+		return nil
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil
+	}
+	return account
+}
+
+func accountSessionsURL(id int64) string {
+	return "/admin/accounts/" + strconv.FormatInt(id, 10) + "/sessions"
+}
+
+// AccountSessions lists the active sessions of the {id} account.
+func (h *AdminHandler) AccountSessions(w http.ResponseWriter, r *http.Request) {
+	account := h.getAccount(w, r)
+	if account == nil {
+		return
+	}
+
+	sessions, err := h.app.Sessions.ListAccountSessions(r.Context(), account.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	identities, err := h.app.Accounts.ListIdentities(r.Context(), account.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data := map[string]any{
+		"Username":   username(r),
+		"Account":    account,
+		"Identities": identities,
+		"Sessions":   sessionRows(r, sessions),
+		"RevokeBase": accountSessionsURL(account.ID),
+	}
+
+	err = h.templates.ExecuteTemplate(w, "admin_account_sessions.html", data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// RevokeAccountSession signs the {session} session of the {id} account
+// out.
+func (h *AdminHandler) RevokeAccountSession(w http.ResponseWriter, r *http.Request) {
+	account := h.getAccount(w, r)
+	if account == nil {
+		return
+	}
+
+	sessions, err := h.app.Sessions.ListAccountSessions(r.Context(), account.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	session := findSession(sessions, chi.URLParam(r, "session"))
+	if session == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if err := h.app.Sessions.DeleteSession(r.Context(), session.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, accountSessionsURL(account.ID), http.StatusSeeOther)
+}
+
+// RevokeAccountSessions signs the {id} account out everywhere.
+func (h *AdminHandler) RevokeAccountSessions(w http.ResponseWriter, r *http.Request) {
+	account := h.getAccount(w, r)
+	if account == nil {
+		return
+	}
+
+	if _, err := h.app.Sessions.DeleteAccountSessions(r.Context(), account.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, accountSessionsURL(account.ID), http.StatusSeeOther)
+}
@@ -0,0 +1,265 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gochi-demo/internal/app"
+	"github.com/gochi-demo/internal/auth"
+	"github.com/gochi-demo/internal/database"
+	"github.com/gochi-demo/internal/render"
+	"github.com/gochi-demo/internal/web"
+)
+
+// adminPageSize is the number of posts per page of the admin post list.
+const adminPageSize = 20
+
+// AdminHandler serves the /admin pages. Its routes are meant to sit behind
+// auth.RequireAuth and auth.RequireAdmin.
+type AdminHandler struct {
+	app       *app.App
+	templates *web.Templates
+}
+
+func NewAdminHandler(app *app.App, templates *web.Templates) *AdminHandler {
+	return &AdminHandler{app: app, templates: templates}
+}
+
+// Routes mounts the admin pages on r.
+func (h *AdminHandler) Routes(r chi.Router) {
+	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/admin/posts", http.StatusSeeOther)
+	})
+	r.Get("/posts", h.ListPosts)
+	r.Get("/posts/new", h.NewPost)
+	r.Post("/posts", h.CreatePost)
+	r.Get("/posts/{id}/edit", h.EditPost)
+	r.Post("/posts/{id}", h.UpdatePost)
+	r.Post("/posts/{id}/delete", h.DeletePost)
+	r.Get("/posts/{id}/revisions", h.ListRevisions)
+	r.Post("/posts/{id}/revisions/{rev}/restore", h.RestoreRevision)
+	r.Post("/preview", h.RenderPreview)
+	r.Post("/uploads", h.Upload)
+	r.Get("/comments", h.ListComments)
+	r.Post("/comments/{id}/{action}", h.ModerateComment)
+	r.Get("/accounts", h.ListAccounts)
+	r.Get("/accounts/{id}/sessions", h.AccountSessions)
+	r.Post("/accounts/{id}/sessions/revoke", h.RevokeAccountSessions)
+	r.Post("/accounts/{id}/sessions/{session}/revoke", h.RevokeAccountSession)
+}
+
+// adminPost is a row of the admin post list.
+type adminPost struct {
+	database.Post
+	PreviewURL string
+}
+
+func (h *AdminHandler) ListPosts(w http.ResponseWriter, r *http.Request) {
+	total, err := h.app.Posts.CountAll(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	pagination, ok := newPagination(r, total, adminPageSize)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	posts, err := h.app.Posts.ListAll(r.Context(), pagination.PerPage, pagination.Offset())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rows := make([]adminPost, len(posts))
+	for i := range posts {
+		rows[i] = adminPost{Post: posts[i], PreviewURL: h.app.PreviewURL(&posts[i])}
+	}
+
+	data := map[string]any{
+		"Username":   username(r),
+		"Posts":      rows,
+		"Pagination": pagination,
+	}
+
+	err = h.templates.ExecuteTemplate(w, "admin_posts.html", data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *AdminHandler) NewPost(w http.ResponseWriter, r *http.Request) {
+	h.renderForm(w, r, nil, newPostForm(&database.Post{}, nil), http.StatusOK)
+}
+
+func (h *AdminHandler) CreatePost(w http.ResponseWriter, r *http.Request) {
+	form := parsePostForm(r)
+	ok, err := form.validate(r.Context(), h.app.Posts, 0)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		h.renderForm(w, r, nil, form, http.StatusUnprocessableEntity)
+		return
+	}
+
+	var post database.Post
+	form.apply(&post)
+	post.Author = editorName(r)
+
+	if err := h.app.CreatePost(r.Context(), &post, post.Author); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := form.saveSeries(r.Context(), h.app.Series, post.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.app.RefreshRelated()
+	http.Redirect(w, r, editURL(post.ID)+"?saved=1", http.StatusSeeOther)
+}
+
+func (h *AdminHandler) EditPost(w http.ResponseWriter, r *http.Request) {
+	post := h.getPost(w, r)
+	if post == nil {
+		return
+	}
+
+	series, err := h.app.Series.ForPost(r.Context(), post.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.renderForm(w, r, post, newPostForm(post, series), http.StatusOK)
+}
+
+func (h *AdminHandler) UpdatePost(w http.ResponseWriter, r *http.Request) {
+	post := h.getPost(w, r)
+	if post == nil {
+		return
+	}
+
+	form := parsePostForm(r)
+	ok, err := form.validate(r.Context(), h.app.Posts, post.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		h.renderForm(w, r, post, form, http.StatusUnprocessableEntity)
+		return
+	}
+
+	form.apply(post)
+	if err := h.app.UpdatePost(r.Context(), post, editorName(r)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := form.saveSeries(r.Context(), h.app.Series, post.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.app.RefreshRelated()
+	http.Redirect(w, r, editURL(post.ID)+"?saved=1", http.StatusSeeOther)
+}
+
+func (h *AdminHandler) DeletePost(w http.ResponseWriter, r *http.Request) {
+	post := h.getPost(w, r)
+	if post == nil {
+		return
+	}
+
+	if err := h.app.Posts.Delete(r.Context(), post.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.app.RefreshRelated()
+	http.Redirect(w, r, "/admin/posts", http.StatusSeeOther)
+}
+
+// RenderPreview renders the Markdown of the body form value, for the live
+// preview of the post form.
+func (h *AdminHandler) RenderPreview(w http.ResponseWriter, r *http.Request) {
+	doc, err := render.Markdown(r.PostFormValue("body"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(doc.HTML))
+}
+
+// getPost loads the post named by the {id} URL param. It writes the error
+// response itself and returns nil when the post can't be served.
+func (h *AdminHandler) getPost(w http.ResponseWriter, r *http.Request) *database.Post {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return nil
+	}
+
+	post, err := h.app.Posts.GetByID(r.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.NotFound(w, r)
+		return nil
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil
+	}
+	return post
+}
+
+// renderForm renders the post form, for a new post when post is nil.
+func (h *AdminHandler) renderForm(w http.ResponseWriter, r *http.Request, post *database.Post, form *postForm, status int) {
+	data := map[string]any{
+		"Username": username(r),
+		"Form":     form,
+		"Statuses": database.Statuses,
+		"Action":   "/admin/posts",
+		"Saved":    r.URL.Query().Get("saved") != "",
+	}
+	if post != nil {
+		data["Post"] = post
+		data["Action"] = "/admin/posts/" + strconv.FormatInt(post.ID, 10)
+		data["PreviewURL"] = h.app.PreviewURL(post)
+	}
+
+	// Render first, the status has to be written before the body.
+	var buf bytes.Buffer
+	if err := h.templates.ExecuteTemplate(&buf, "admin_post_form.html", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	buf.WriteTo(w)
+}
+
+// editorName returns the name saved with the revisions written by the
+// signed in user.
+func editorName(r *http.Request) string {
+	user, err := auth.GetUserFromContext(r)
+	if err != nil {
+		return ""
+	}
+	if user.Name != "" {
+		return user.Name
+	}
+	return user.Email
+}
+
+func editURL(id int64) string {
+	return "/admin/posts/" + strconv.FormatInt(id, 10) + "/edit"
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gochi-demo/internal/app"
+	"github.com/gochi-demo/internal/database"
+	"github.com/gochi-demo/internal/web"
+)
+
+type ArchiveHandler struct {
+	app       *app.App
+	templates *web.Templates
+}
+
+func NewArchiveHandler(app *app.App, templates *web.Templates) *ArchiveHandler {
+	return &ArchiveHandler{app: app, templates: templates}
+}
+
+// archiveMonth is a month heading of an archive page with the posts of
+// the current page published that month. Count covers the whole month.
+type archiveMonth struct {
+	database.MonthCount
+	Posts []database.Post
+}
+
+// Archive lists every published post, newest first, grouped by month.
+func (h *ArchiveHandler) Archive(w http.ResponseWriter, r *http.Request) {
+	h.render(w, r, "Archive", "", "", h.app.Posts.Count, h.app.Posts.List, nil)
+}
+
+// Year lists the posts published during the {year}.
+func (h *ArchiveHandler) Year(w http.ResponseWriter, r *http.Request) {
+	year, _ := strconv.Atoi(chi.URLParam(r, "year"))
+	from := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	h.renderRange(w, r, strconv.Itoa(year), "/archive", "the archive", from, from.AddDate(1, 0, 0))
+}
+
+// Month lists the posts published during the {month} of the {year}.
+func (h *ArchiveHandler) Month(w http.ResponseWriter, r *http.Request) {
+	year, _ := strconv.Atoi(chi.URLParam(r, "year"))
+	month, _ := strconv.Atoi(chi.URLParam(r, "month"))
+	if month < 1 || month > 12 {
+		http.NotFound(w, r)
+		return
+	}
+	from := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	h.renderRange(w, r, from.Format("January 2006"), database.YearURL(year), strconv.Itoa(year), from, from.AddDate(0, 1, 0))
+}
+
+// renderRange renders the archive page of the posts published from from,
+// included, to to, excluded, linking up to upURL. It answers 404 when
+// there are none.
+func (h *ArchiveHandler) renderRange(w http.ResponseWriter, r *http.Request, heading, upURL, upTitle string, from, to time.Time) {
+	count := func(ctx context.Context) (int, error) {
+		return h.app.Posts.CountByDate(ctx, from, to)
+	}
+	list := func(ctx context.Context, limit, offset int) ([]database.Post, error) {
+		return h.app.Posts.ListByDate(ctx, from, to, limit, offset)
+	}
+	inRange := func(m database.MonthCount) bool {
+		start := m.Start()
+		return !start.Before(from) && start.Before(to)
+	}
+	h.render(w, r, heading, upURL, upTitle, count, list, inRange)
+}
+
+// render renders an archive page listing posts with count and list, as
+// loadPostPage does. The month index only shows the months accepted by
+// keep, every month when keep is nil.
+func (h *ArchiveHandler) render(
+	w http.ResponseWriter, r *http.Request, heading, upURL, upTitle string,
+	count func(ctx context.Context) (int, error),
+	list func(ctx context.Context, limit, offset int) ([]database.Post, error),
+	keep func(database.MonthCount) bool,
+) {
+	posts, pagination, ok := loadPostPage(w, r, h.app, count, list)
+	if !ok {
+		return
+	}
+	if pagination.Total == 0 && keep != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	all, err := h.app.Posts.ListMonths(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var months []database.MonthCount
+	for _, m := range all {
+		if keep == nil || keep(m) {
+			months = append(months, m)
+		}
+	}
+
+	data := map[string]any{
+		"Username":   username(r),
+		"Heading":    heading,
+		"UpURL":      upURL,
+		"UpTitle":    upTitle,
+		"Months":     months,
+		"Groups":     groupByMonth(posts, months),
+		"Pagination": pagination,
+	}
+
+	err = h.templates.ExecuteTemplate(w, "archive.html", data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// groupByMonth splits posts, newest first, by the month they were
+// published, taking the counts from months.
+func groupByMonth(posts []database.Post, months []database.MonthCount) []archiveMonth {
+	counts := make(map[time.Time]int, len(months))
+	for _, m := range months {
+		counts[m.Start()] = m.Count
+	}
+
+	var groups []archiveMonth
+	for _, p := range posts {
+		t := p.PublishedAt.UTC()
+		m := database.MonthCount{Year: t.Year(), Month: int(t.Month())}
+		if n := len(groups); n == 0 || groups[n-1].MonthCount != m {
+			groups = append(groups, archiveMonth{MonthCount: m})
+		}
+		groups[len(groups)-1].Posts = append(groups[len(groups)-1].Posts, p)
+	}
+	for i := range groups {
+		groups[i].Count = counts[groups[i].Start()]
+	}
+	return groups
+}
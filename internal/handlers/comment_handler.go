@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gochi-demo/internal/app"
+	"github.com/gochi-demo/internal/auth"
+	"github.com/gochi-demo/internal/database"
+)
+
+type CommentHandler struct {
+	app *app.App
+}
+
+func NewCommentHandler(app *app.App) *CommentHandler {
+	return &CommentHandler{app: app}
+}
+
+// commentNotices are the messages shown above the comment form, keyed by
+// the ?comment= value PostComment redirects with.
+var commentNotices = map[string]string{
+	"pending": "Thanks! Your comment will show up once a moderator approves it.",
+	"empty":   "Your comment was empty.",
+	"long":    "Your comment is too long, please keep it under " + strconv.Itoa(app.MaxCommentLength) + " characters.",
+}
+
+// PostComment adds a comment, or a reply when parent_id is set, to the
+// {slug} post. Only signed in users can comment and every comment waits for
+// moderation.
+func (h *CommentHandler) PostComment(w http.ResponseWriter, r *http.Request) {
+	user, err := auth.GetUserFromSession(r)
+	if err != nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	post := getPublishedPost(w, r, h.app)
+	if post == nil {
+		return
+	}
+
+	body := strings.TrimSpace(r.PostFormValue("body"))
+	notice := "pending"
+	switch {
+	case body == "":
+		notice = "empty"
+	case utf8.RuneCountInString(body) > app.MaxCommentLength:
+		notice = "long"
+	}
+	if notice != "pending" {
+		http.Redirect(w, r, post.URL()+"?comment="+notice+"#comment-form", http.StatusSeeOther)
+		return
+	}
+
+	c := &database.Comment{
+		PostID:       post.ID,
+		AuthorID:     strconv.FormatInt(user.ID, 10),
+		AuthorName:   commenterName(user),
+		AuthorAvatar: user.AvatarURL,
+		Body:         body,
+	}
+
+	if id, err := strconv.ParseInt(r.PostFormValue("parent_id"), 10, 64); err == nil {
+		parent, err := h.app.Comments.GetByID(r.Context(), id)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if parent == nil || parent.PostID != post.ID || parent.Status != database.CommentApproved {
+			http.Error(w, "unknown parent comment", http.StatusBadRequest)
+			return
+		}
+		c.ParentID = &parent.ID
+	}
+
+	if err := h.app.Comments.Create(r.Context(), c); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, post.URL()+"?comment=pending#comment-form", http.StatusSeeOther)
+}
+
+func commenterName(user *auth.User) string {
+	if user.Name != "" {
+		return user.Name
+	}
+	if user.FirstName != "" {
+		return strings.TrimSpace(user.FirstName + " " + user.LastName)
+	}
+	return "Anonymous"
+}
+
+// findComment returns the comment of threads, replies included, whose id is
+// param, or nil.
+func findComment(threads []*database.Comment, param string) *database.Comment {
+	id, err := strconv.ParseInt(param, 10, 64)
+	if err != nil {
+		return nil
+	}
+	for _, c := range threads {
+		if c.ID == id {
+			return c
+		}
+		if found := findComment(c.Replies, param); found != nil {
+			return found
+		}
+	}
+	return nil
+}
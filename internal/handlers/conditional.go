@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"time"
+)
+
+// serveConditional serves body with an ETag and, unless modTime is zero, a
+// Last-Modified date, so clients revalidating a cached copy get a 304 when
+// nothing changed.
+func serveConditional(w http.ResponseWriter, r *http.Request, contentType string, modTime time.Time, body []byte) {
+	sum := sha256.Sum256(body)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+
+	http.ServeContent(w, r, "", modTime, bytes.NewReader(body))
+}
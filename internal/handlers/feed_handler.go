@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gochi-demo/internal/app"
+	"github.com/gochi-demo/internal/config"
+	"github.com/gochi-demo/internal/database"
+	"github.com/gochi-demo/internal/feed"
+)
+
+// defaultFeedSize is the number of posts in a feed when FEED_SIZE is not set.
+const defaultFeedSize = 20
+
+type FeedHandler struct {
+	app *app.App
+}
+
+func NewFeedHandler(app *app.App) *FeedHandler {
+	return &FeedHandler{app: app}
+}
+
+func (h *FeedHandler) RSS(w http.ResponseWriter, r *http.Request) {
+	h.serveRecent(w, r, "/rss.xml", "application/rss+xml; charset=utf-8", (*feed.Feed).RSS)
+}
+
+func (h *FeedHandler) Atom(w http.ResponseWriter, r *http.Request) {
+	h.serveRecent(w, r, "/atom.xml", "application/atom+xml; charset=utf-8", (*feed.Feed).Atom)
+}
+
+func (h *FeedHandler) JSON(w http.ResponseWriter, r *http.Request) {
+	h.serveRecent(w, r, "/feed.json", "application/feed+json; charset=utf-8", (*feed.Feed).JSON)
+}
+
+// TagRSS serves the RSS feed of the posts tagged with {tag}.
+func (h *FeedHandler) TagRSS(w http.ResponseWriter, r *http.Request) {
+	tag := tagParam(r)
+
+	posts, err := h.app.Posts.ListByTag(r.Context(), tag, feedSize(), 0)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(posts) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	site := config.GetSite()
+	f, err := h.buildFeed(posts, database.TagURL(tag), database.TagURL(tag)+"/feed.xml")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	f.Title = site.Title + " - #" + tag
+
+	serveFeed(w, r, f, "application/rss+xml; charset=utf-8", (*feed.Feed).RSS)
+}
+
+// serveRecent serves the feed of the latest posts, served at path.
+func (h *FeedHandler) serveRecent(w http.ResponseWriter, r *http.Request, path, contentType string, encode func(*feed.Feed) ([]byte, error)) {
+	posts, err := h.app.Posts.List(r.Context(), feedSize(), 0)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	f, err := h.buildFeed(posts, "/", path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	serveFeed(w, r, f, contentType, encode)
+}
+
+// buildFeed turns posts into a feed of the page at link, served at path.
+// Items carry the full post or only its summary depending on FEED_CONTENT.
+func (h *FeedHandler) buildFeed(posts []database.Post, link, path string) (*feed.Feed, error) {
+	site := config.GetSite()
+	fullContent := !strings.EqualFold(config.GetConfigWithDefault("FEED_CONTENT", "full"), "summary")
+
+	f := &feed.Feed{
+		Title:       site.Title,
+		Description: site.Description,
+		Link:        site.URL + link,
+		FeedURL:     site.URL + path,
+		Author:      site.Author,
+	}
+
+	for i := range posts {
+		p := &posts[i]
+		doc, err := h.app.RenderPost(p)
+		if err != nil {
+			return nil, err
+		}
+
+		item := feed.Item{
+			URL:       site.URL + p.URL(),
+			Title:     p.Title,
+			Summary:   p.Summary,
+			Author:    p.Author,
+			Tags:      p.Tags,
+			Published: p.PublishedAt,
+			Updated:   p.UpdatedAt,
+		}
+		if fullContent {
+			item.Content = string(doc.HTML)
+		}
+		f.Items = append(f.Items, item)
+	}
+
+	return f, nil
+}
+
+// serveFeed encodes f and serves it with an ETag and a Last-Modified date,
+// so polling clients get a 304 when nothing changed.
+func serveFeed(w http.ResponseWriter, r *http.Request, f *feed.Feed, contentType string, encode func(*feed.Feed) ([]byte, error)) {
+	body, err := encode(f)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	serveConditional(w, r, contentType, f.Updated(), body)
+}
+
+func feedSize() int {
+	n := config.GetIntConfigWithDefault("FEED_SIZE", defaultFeedSize)
+	if n < 1 {
+		return defaultFeedSize
+	}
+	return n
+}
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gochi-demo/internal/app"
+	"github.com/gochi-demo/internal/database"
+	"github.com/gochi-demo/internal/database/dbtest"
+)
+
+// testPosts creates a published, a draft and a scheduled post, keyed by
+// status.
+func testPosts(t *testing.T, a *app.App) map[string]*database.Post {
+	t.Helper()
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	posts := map[string]*database.Post{
+		database.StatusPublished: {PublishedAt: now.Add(-time.Minute)},
+		database.StatusDraft:     {},
+		database.StatusScheduled: {PublishedAt: now.Add(time.Hour)},
+	}
+	for status, p := range posts {
+		p.Slug = dbtest.ID(t) + "-" + status
+		p.Title = "A " + status + " post"
+		p.Body = "Some text."
+		p.Tags = []string{"feedtest"}
+		p.Status = status
+		if err := a.CreatePost(ctx, p, "test"); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { a.Posts.Delete(ctx, p.ID) })
+	}
+	return posts
+}
+
+// checkListed reports whether links holds the published post of posts and
+// none of the others.
+func checkListed(t *testing.T, links []string, posts map[string]*database.Post) {
+	t.Helper()
+	listed := func(p *database.Post) bool {
+		for _, link := range links {
+			if strings.HasSuffix(link, p.URL()) {
+				return true
+			}
+		}
+		return false
+	}
+	for status, p := range posts {
+		if want := status == database.StatusPublished; listed(p) != want {
+			t.Errorf("%s post listed: %v; want %v", status, !want, want)
+		}
+	}
+}
+
+func TestFeeds(t *testing.T) {
+	tests := []struct {
+		name  string
+		path  string
+		serve func(*FeedHandler, http.ResponseWriter, *http.Request)
+		links func(body []byte) ([]string, error)
+	}{
+		{"rss", "/rss.xml", (*FeedHandler).RSS, func(body []byte) ([]string, error) {
+			var doc struct {
+				Items []struct {
+					Link string `xml:"link"`
+				} `xml:"channel>item"`
+			}
+			err := xml.Unmarshal(body, &doc)
+			var links []string
+			for _, it := range doc.Items {
+				links = append(links, it.Link)
+			}
+			return links, err
+		}},
+		{"atom", "/atom.xml", (*FeedHandler).Atom, func(body []byte) ([]string, error) {
+			var doc struct {
+				Entries []struct {
+					ID string `xml:"id"`
+				} `xml:"entry"`
+			}
+			err := xml.Unmarshal(body, &doc)
+			var links []string
+			for _, e := range doc.Entries {
+				links = append(links, e.ID)
+			}
+			return links, err
+		}},
+		{"json", "/feed.json", (*FeedHandler).JSON, func(body []byte) ([]string, error) {
+			var doc struct {
+				Items []struct {
+					URL string `json:"url"`
+				} `json:"items"`
+			}
+			err := json.Unmarshal(body, &doc)
+			var links []string
+			for _, it := range doc.Items {
+				links = append(links, it.URL)
+			}
+			return links, err
+		}},
+	}
+
+	for _, b := range dbtest.Backends(t) {
+		t.Run(b.Name, func(t *testing.T) {
+			a := &app.App{Posts: b.Posts}
+			posts := testPosts(t, a)
+			h := NewFeedHandler(a)
+
+			for _, tt := range tests {
+				w := httptest.NewRecorder()
+				tt.serve(h, w, httptest.NewRequest("GET", tt.path, nil))
+				if w.Code != http.StatusOK {
+					t.Fatalf("%s: status %d: %s", tt.name, w.Code, w.Body)
+				}
+				links, err := tt.links(w.Body.Bytes())
+				if err != nil {
+					t.Fatalf("%s: %v", tt.name, err)
+				}
+				checkListed(t, links, posts)
+			}
+		})
+	}
+}
+
+func TestFeedConditionalGet(t *testing.T) {
+	for _, b := range dbtest.Backends(t) {
+		t.Run(b.Name, func(t *testing.T) {
+			a := &app.App{Posts: b.Posts}
+			testPosts(t, a)
+			h := NewFeedHandler(a)
+
+			w := httptest.NewRecorder()
+			h.RSS(w, httptest.NewRequest("GET", "/rss.xml", nil))
+			etag, lastModified := w.Header().Get("ETag"), w.Header().Get("Last-Modified")
+			if etag == "" || lastModified == "" {
+				t.Fatalf("ETag %q, Last-Modified %q; want both set", etag, lastModified)
+			}
+
+			tests := []struct {
+				header, value string
+				want          int
+			}{
+				{"If-None-Match", etag, http.StatusNotModified},
+				{"If-None-Match", `"stale"`, http.StatusOK},
+				{"If-Modified-Since", lastModified, http.StatusNotModified},
+				{"If-Modified-Since", "Mon, 02 Jan 2006 15:04:05 GMT", http.StatusOK},
+			}
+			for _, tt := range tests {
+				r := httptest.NewRequest("GET", "/rss.xml", nil)
+				r.Header.Set(tt.header, tt.value)
+				w := httptest.NewRecorder()
+				h.RSS(w, r)
+				if w.Code != tt.want {
+					t.Errorf("%s: %s: status %d; want %d", tt.header, tt.value, w.Code, tt.want)
+				}
+			}
+		})
+	}
+}
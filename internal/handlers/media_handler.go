@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gochi-demo/internal/app"
+	"github.com/gochi-demo/internal/storage"
+)
+
+type MediaHandler struct {
+	app *app.App
+}
+
+func NewMediaHandler(app *app.App) *MediaHandler {
+	return &MediaHandler{app: app}
+}
+
+// Serve serves the blob named by the rest of the /media/ path. Uploaded
+// files never change under a given name, so they are cached for a year.
+func (h *MediaHandler) Serve(w http.ResponseWriter, r *http.Request) {
+	obj, info, err := h.app.Blobs.Get(r.Context(), chi.URLParam(r, "*"))
+	if errors.Is(err, storage.ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer obj.Close()
+
+	if info.ContentType != "" {
+		w.Header().Set("Content-Type", info.ContentType)
+	}
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("ETag", fmt.Sprintf(`"%x-%x"`, info.ModTime.Unix(), info.Size))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	http.ServeContent(w, r, "", info.ModTime, obj)
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gochi-demo/internal/config"
+	"github.com/gochi-demo/internal/database"
+	"github.com/gochi-demo/internal/socialcard"
+)
+
+// PageMeta is what the layout puts in the canonical link, Open Graph and
+// Twitter card tags of a page, so shared links get a preview.
+type PageMeta struct {
+	Type        string
+	SiteName    string
+	Title       string
+	Description string
+	// URL and Image are absolute, as social sites require.
+	URL           string
+	Image         string
+	ImageWidth    int
+	ImageHeight   int
+	Author        string
+	PublishedTime string
+	ModifiedTime  string
+	Tags          []string
+}
+
+// postMeta returns the page metadata of p, whose social card is at
+// version cardVersion. p must have been rendered for its summary.
+func postMeta(p *database.Post, cardVersion int64) *PageMeta {
+	site := config.GetSite()
+	return &PageMeta{
+		Type:          "article",
+		SiteName:      site.Title,
+		Title:         p.Title,
+		Description:   p.Summary,
+		URL:           site.URL + p.URL(),
+		Image:         fmt.Sprintf("%s%s/card.png?v=%d", site.URL, p.URL(), cardVersion),
+		ImageWidth:    socialcard.Width,
+		ImageHeight:   socialcard.Height,
+		Author:        p.Author,
+		PublishedTime: p.PublishedAt.UTC().Format(time.RFC3339),
+		ModifiedTime:  p.UpdatedAt.UTC().Format(time.RFC3339),
+		Tags:          p.Tags,
+	}
+}
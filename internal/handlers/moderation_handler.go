@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gochi-demo/internal/database"
+	"github.com/gochi-demo/internal/render"
+)
+
+// moderationActions maps the actions of the moderation queue to the
+// status they give a comment.
+var moderationActions = map[string]string{
+	"approve": database.CommentApproved,
+	"reject":  database.CommentRejected,
+	"spam":    database.CommentSpam,
+}
+
+// commentTab is a status tab of the moderation queue.
+type commentTab struct {
+	Status string
+	Count  int
+}
+
+// ListComments lists the comments with the ?status= status, pending by
+// default, newest first.
+func (h *AdminHandler) ListComments(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = database.CommentPending
+	}
+	if !slices.Contains(database.CommentStatuses, status) {
+		http.NotFound(w, r)
+		return
+	}
+
+	tabs := make([]commentTab, len(database.CommentStatuses))
+	total := 0
+	for i, s := range database.CommentStatuses {
+		n, err := h.app.Comments.CountByStatus(r.Context(), s)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		tabs[i] = commentTab{Status: s, Count: n}
+		if s == status {
+			total = n
+		}
+	}
+
+	pagination, ok := newPagination(r, total, adminPageSize)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	comments, err := h.app.Comments.ListByStatus(r.Context(), status, pagination.PerPage, pagination.Offset())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for i := range comments {
+		if comments[i].HTML, err = render.Comment(comments[i].Body); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	data := map[string]any{
+		"Username":   username(r),
+		"Status":     status,
+		"Tabs":       tabs,
+		"Comments":   comments,
+		"Pagination": pagination,
+	}
+
+	err = h.templates.ExecuteTemplate(w, "admin_comments.html", data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// ModerateComment applies the {action} of moderationActions to the {id}
+// comment and goes back to the ?status= tab it came from.
+func (h *AdminHandler) ModerateComment(w http.ResponseWriter, r *http.Request) {
+	status, ok := moderationActions[chi.URLParam(r, "action")]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	err = h.app.Comments.SetStatus(r.Context(), id, status)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	back := database.CommentPending
+	if s := r.URL.Query().Get("status"); slices.Contains(database.CommentStatuses, s) {
+		back = s
+	}
+	http.Redirect(w, r, "/admin/comments?status="+url.QueryEscape(back), http.StatusSeeOther)
+}
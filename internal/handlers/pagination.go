@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gochi-demo/internal/config"
+)
+
+// defaultPostsPerPage is the page size used when POSTS_PER_PAGE is not set.
+const defaultPostsPerPage = 10
+
+// Pagination describes the current page of a paginated listing.
+type Pagination struct {
+	Page       int    `json:"page"`
+	TotalPages int    `json:"total_pages"`
+	PerPage    int    `json:"per_page"`
+	Total      int    `json:"total"`
+	PrevURL    string `json:"prev_url,omitempty"`
+	NextURL    string `json:"next_url,omitempty"`
+}
+
+// Offset is the number of items before the current page.
+func (p Pagination) Offset() int {
+	return (p.Page - 1) * p.PerPage
+}
+
+func postsPerPage() int {
+	n := config.GetIntConfigWithDefault("POSTS_PER_PAGE", defaultPostsPerPage)
+	if n < 1 {
+		return defaultPostsPerPage
+	}
+	return n
+}
+
+// newPagination reads the ?page= query param of r for a listing of total
+// items. It reports false when the param is malformed or past the last page.
+func newPagination(r *http.Request, total, perPage int) (Pagination, bool) {
+	p := Pagination{Page: 1, PerPage: perPage, Total: total}
+
+	if v := r.URL.Query().Get("page"); v != "" {
+		page, err := strconv.Atoi(v)
+		if err != nil || page < 1 {
+			return p, false
+		}
+		p.Page = page
+	}
+
+	p.TotalPages = (total + perPage - 1) / perPage
+	if p.TotalPages < 1 {
+		p.TotalPages = 1
+	}
+	if p.Page > p.TotalPages {
+		return p, false
+	}
+
+	if p.Page > 1 {
+		p.PrevURL = pageURL(r, p.Page-1)
+	}
+	if p.Page < p.TotalPages {
+		p.NextURL = pageURL(r, p.Page+1)
+	}
+
+	return p, true
+}
+
+// pageURL returns the URL of page n of the listing served at r, keeping
+// the other query params. Page 1 has no page param at all.
+func pageURL(r *http.Request, n int) string {
+	q := r.URL.Query()
+	if n <= 1 {
+		q.Del("page")
+	} else {
+		q.Set("page", strconv.Itoa(n))
+	}
+
+	if len(q) == 0 {
+		return r.URL.Path
+	}
+	return r.URL.Path + "?" + q.Encode()
+}
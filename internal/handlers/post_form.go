@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gochi-demo/internal/app"
+	"github.com/gochi-demo/internal/database"
+)
+
+// formTimeLayout is the format of <input type="datetime-local">. Times are
+// entered and shown in UTC.
+const formTimeLayout = "2006-01-02T15:04"
+
+// postForm holds the fields of the admin post form as typed, so they can be
+// shown back along with Errors, keyed by field name.
+type postForm struct {
+	Title       string
+	Slug        string
+	Body        string
+	Tags        string
+	Status      string
+	PublishedAt string
+	Series      string
+	Part        string
+	Errors      map[string]string
+
+	publishedAt time.Time
+	seriesSlug  string
+	part        int
+}
+
+// newPostForm fills in the form from p and series, the series of p or nil.
+func newPostForm(p *database.Post, series *database.Series) *postForm {
+	f := &postForm{
+		Title:  p.Title,
+		Slug:   p.Slug,
+		Body:   p.Body,
+		Tags:   strings.Join(p.Tags, ", "),
+		Status: p.Status,
+	}
+	if !p.PublishedAt.IsZero() {
+		f.PublishedAt = p.PublishedAt.UTC().Format(formTimeLayout)
+	}
+	if f.Status == "" {
+		f.Status = database.StatusDraft
+	}
+	if series != nil {
+		f.Series = series.Title
+		f.Part = strconv.Itoa(series.Position)
+	}
+	return f
+}
+
+func parsePostForm(r *http.Request) *postForm {
+	return &postForm{
+		Title:       strings.TrimSpace(r.PostFormValue("title")),
+		Slug:        strings.TrimSpace(r.PostFormValue("slug")),
+		Body:        r.PostFormValue("body"),
+		Tags:        r.PostFormValue("tags"),
+		Status:      r.PostFormValue("status"),
+		PublishedAt: strings.TrimSpace(r.PostFormValue("published_at")),
+		Series:      strings.TrimSpace(r.PostFormValue("series")),
+		Part:        strings.TrimSpace(r.PostFormValue("part")),
+	}
+}
+
+// validate checks the form for the post with the given id, 0 for a new
+// post, and fills in Errors. It reports whether the form is valid.
+func (f *postForm) validate(ctx context.Context, posts database.PostStore, id int64) (bool, error) {
+	f.Errors = map[string]string{}
+
+	if f.Title == "" {
+		f.Errors["Title"] = "Title is required."
+	}
+	if f.Slug == "" {
+		f.Slug = app.Slugify(f.Title)
+	}
+	if f.Slug == "" {
+		f.Errors["Slug"] = "Slug is required."
+	} else if !app.ValidSlug(f.Slug) {
+		f.Errors["Slug"] = "Use lowercase letters, digits and single dashes only."
+	} else {
+		other, err := posts.GetBySlug(ctx, f.Slug)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return false, err
+		}
+		if other != nil && other.ID != id {
+			f.Errors["Slug"] = "Another post already uses this slug."
+		}
+	}
+	if strings.TrimSpace(f.Body) == "" {
+		f.Errors["Body"] = "Body is required."
+	}
+
+	validStatus := false
+	for _, s := range database.Statuses {
+		validStatus = validStatus || f.Status == s
+	}
+	if !validStatus {
+		f.Errors["Status"] = "Pick a status."
+	}
+
+	if f.PublishedAt != "" {
+		t, err := time.ParseInLocation(formTimeLayout, f.PublishedAt, time.UTC)
+		if err != nil {
+			f.Errors["PublishedAt"] = "Use the YYYY-MM-DDTHH:MM format."
+		}
+		f.publishedAt = t
+	}
+	if f.Status == database.StatusScheduled && f.Errors["PublishedAt"] == "" {
+		if f.publishedAt.IsZero() {
+			f.Errors["PublishedAt"] = "Scheduled posts need a publish date."
+		} else if !f.publishedAt.After(time.Now()) {
+			f.Errors["PublishedAt"] = "Scheduled posts need a publish date in the future."
+		}
+	}
+
+	if f.Series != "" {
+		f.seriesSlug = app.Slugify(f.Series)
+		if f.seriesSlug == "" {
+			f.Errors["Series"] = "Use at least one letter or digit."
+		}
+	}
+	if f.Part != "" {
+		n, err := strconv.Atoi(f.Part)
+		if err != nil || n < 1 {
+			f.Errors["Part"] = "Use a whole number from 1 up."
+		}
+		f.part = n
+	}
+
+	return len(f.Errors) == 0, nil
+}
+
+// apply copies the validated form into p. Drafts may have no date, the
+// post store stamps one when they are published.
+func (f *postForm) apply(p *database.Post) {
+	p.Title = f.Title
+	p.Slug = f.Slug
+	p.Body = f.Body
+	p.Tags = strings.Split(f.Tags, ",")
+	p.Status = f.Status
+	p.PublishedAt = f.publishedAt
+}
+
+// saveSeries puts the post with the given id into the series of the
+// validated form, or takes it out of its series when the field is empty.
+func (f *postForm) saveSeries(ctx context.Context, series database.SeriesStore, postID int64) error {
+	return series.SetPostSeries(ctx, postID, f.seriesSlug, f.Series, f.part)
+}
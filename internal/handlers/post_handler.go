@@ -0,0 +1,328 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gochi-demo/internal/app"
+	"github.com/gochi-demo/internal/auth"
+	"github.com/gochi-demo/internal/database"
+	"github.com/gochi-demo/internal/web"
+)
+
+type PostHandler struct {
+	app       *app.App
+	templates *web.Templates
+}
+
+func NewPostHandler(app *app.App, templates *web.Templates) *PostHandler {
+	return &PostHandler{app: app, templates: templates}
+}
+
+// getPost loads the post named by the {slug} URL param, whatever its
+// status. It writes the error response itself and returns nil when the
+// post can't be served.
+func getPost(w http.ResponseWriter, r *http.Request, a *app.App) *database.Post {
+	slug := chi.URLParam(r, "slug")
+
+	post, err := a.Posts.GetBySlug(r.Context(), slug)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.NotFound(w, r)
+		return nil
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil
+	}
+	return post
+}
+
+// getPublishedPost is like getPost but answers 404 for posts that are not
+// published.
+func getPublishedPost(w http.ResponseWriter, r *http.Request, a *app.App) *database.Post {
+	post := getPost(w, r, a)
+	if post != nil && !post.IsPublished() {
+		http.NotFound(w, r)
+		return nil
+	}
+	return post
+}
+
+// listPosts loads the page of posts requested by r, newest first. It writes
+// the error response itself and returns false when the page can't be served.
+func (h *PostHandler) listPosts(w http.ResponseWriter, r *http.Request) ([]database.Post, Pagination, bool) {
+	return loadPostPage(w, r, h.app, h.app.Posts.Count, h.app.Posts.List)
+}
+
+// loadPostPage loads the page requested by r of a post listing, using
+// count for the size of the whole listing and list to fetch the page. The
+// posts come back rendered. It writes the error response itself and
+// returns false when the page can't be served.
+func loadPostPage(
+	w http.ResponseWriter, r *http.Request, a *app.App,
+	count func(ctx context.Context) (int, error),
+	list func(ctx context.Context, limit, offset int) ([]database.Post, error),
+) ([]database.Post, Pagination, bool) {
+	total, err := count(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, Pagination{}, false
+	}
+
+	pagination, ok := newPagination(r, total, postsPerPage())
+	if !ok {
+		http.NotFound(w, r)
+		return nil, pagination, false
+	}
+
+	posts, err := list(r.Context(), pagination.PerPage, pagination.Offset())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, pagination, false
+	}
+
+	for i := range posts {
+		if _, err := a.RenderPost(&posts[i]); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return nil, pagination, false
+		}
+	}
+
+	return posts, pagination, true
+}
+
+func (h *PostHandler) Index(w http.ResponseWriter, r *http.Request) {
+	posts, pagination, ok := h.listPosts(w, r)
+	if !ok {
+		return
+	}
+
+	data := map[string]any{
+		"Username":   username(r),
+		"Posts":      posts,
+		"Pagination": pagination,
+	}
+
+	err := h.templates.ExecuteTemplate(w, "index.html", data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *PostHandler) ListPostsJSON(w http.ResponseWriter, r *http.Request) {
+	posts, pagination, ok := h.listPosts(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"posts":      posts,
+		"pagination": pagination,
+	})
+}
+
+func (h *PostHandler) GetPost(w http.ResponseWriter, r *http.Request) {
+	post := getPublishedPost(w, r, h.app)
+	if post == nil {
+		return
+	}
+	h.renderPost(w, r, post, false)
+}
+
+// Preview shows a post of any status to a signed in author holding the
+// token of PreviewURL.
+func (h *PostHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	if !h.app.VerifyPreview(chi.URLParam(r, "slug"), r.URL.Query().Get("token")) {
+		http.NotFound(w, r)
+		return
+	}
+
+	post := getPost(w, r, h.app)
+	if post == nil {
+		return
+	}
+
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.Header().Set("X-Robots-Tag", "noindex")
+	h.renderPost(w, r, post, true)
+}
+
+// renderPost renders the page of post. Previews don't link to the
+// neighbouring posts, have no comments and no social metadata. The series
+// box is only shown once the post is published, as drafts aren't counted
+// as parts.
+func (h *PostHandler) renderPost(w http.ResponseWriter, r *http.Request, post *database.Post, preview bool) {
+	doc, err := h.app.RenderPost(post)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	series, err := h.app.Series.ForPost(r.Context(), post.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Parts of a series link to each other in series order, other posts
+	// in date order.
+	var prev, next *database.Post
+	if !preview {
+		if series != nil && series.Part(post.ID) > 0 {
+			prev, next = series.Adjacent(post.ID)
+		} else {
+			prev, next, err = h.app.Posts.Adjacent(r.Context(), post)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+	}
+
+	// Previews of drafts have no date yet.
+	var date string
+	if !post.PublishedAt.IsZero() {
+		date = post.PublishedAt.Format("2006-01-02")
+	}
+
+	data := map[string]any{
+		"Title":     post.Title,
+		"Username":  username(r),
+		"Date":      date,
+		"Author":    post.Author,
+		"Tags":      post.Tags,
+		"ReadTime":  post.ReadingTime,
+		"WordCount": post.WordCount,
+		"Content":   doc.HTML,
+		"TOC":       doc.TOC,
+		"PrevPost":  prev,
+		"NextPost":  next,
+		"Preview":   preview,
+		"Status":    post.Status,
+	}
+	if series != nil && series.Part(post.ID) > 0 {
+		data["Series"] = series
+		data["SeriesPart"] = series.Part(post.ID)
+		data["PostID"] = post.ID
+	}
+
+	if !preview {
+		version, err := h.app.SocialCardVersion(r.Context(), post)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		data["Meta"] = postMeta(post, version)
+
+		threads, count, err := h.app.CommentThreads(r.Context(), post.ID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		data["URL"] = post.URL()
+		data["Comments"] = threads
+		data["CommentCount"] = count
+		data["CommentNotice"] = commentNotices[r.URL.Query().Get("comment")]
+		_, err = auth.GetUserFromSession(r)
+		data["CanComment"] = err == nil
+		data["ReplyTo"] = findComment(threads, r.URL.Query().Get("reply_to"))
+
+		data["Related"], err = h.app.Related.ListRelated(r.Context(), post.ID, app.RelatedLimit)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	err = h.templates.ExecuteTemplate(w, "post.html", data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// SocialCard serves the PNG image shown when the {slug} post is shared.
+// Pages link to it with the card version in the query, so a cached copy
+// is only reused until the post changes.
+func (h *PostHandler) SocialCard(w http.ResponseWriter, r *http.Request) {
+	post := getPublishedPost(w, r, h.app)
+	if post == nil {
+		return
+	}
+
+	name, err := h.app.SocialCard(r.Context(), post)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	http.ServeContent(w, r, "", info.ModTime(), f)
+}
+
+func (h *PostHandler) GetPostJSON(w http.ResponseWriter, r *http.Request) {
+	post := getPublishedPost(w, r, h.app)
+	if post == nil {
+		return
+	}
+
+	if _, err := h.app.RenderPost(post); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(post)
+}
+
+// RelatedJSON lists the posts related to the {slug} post, best match
+// first.
+func (h *PostHandler) RelatedJSON(w http.ResponseWriter, r *http.Request) {
+	post := getPublishedPost(w, r, h.app)
+	if post == nil {
+		return
+	}
+
+	posts, err := h.app.Related.ListRelated(r.Context(), post.ID, app.RelatedLimit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for i := range posts {
+		if _, err := h.app.RenderPost(&posts[i]); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"posts": posts,
+	})
+}
+
+// username returns the first name of the signed in user, if any.
+func username(r *http.Request) string {
+	user, err := auth.GetUserFromSession(r)
+	if err != nil {
+		return ""
+	}
+	return user.FirstName
+}
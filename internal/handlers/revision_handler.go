@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gochi-demo/internal/database"
+	"github.com/gochi-demo/internal/diff"
+)
+
+// ListRevisions lists the revisions of a post along with the diff between
+// the ?from= and ?to= revisions, by default the last two.
+func (h *AdminHandler) ListRevisions(w http.ResponseWriter, r *http.Request) {
+	post := h.getPost(w, r)
+	if post == nil {
+		return
+	}
+
+	revs, err := h.app.Revisions.ListRevisions(r.Context(), post.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data := map[string]any{
+		"Username":  username(r),
+		"Post":      post,
+		"Revisions": revs,
+	}
+
+	if len(revs) > 0 {
+		to := findRevision(revs, r.URL.Query().Get("to"), &revs[0])
+		from := &revs[0]
+		if len(revs) > 1 {
+			from = &revs[1]
+		}
+		from = findRevision(revs, r.URL.Query().Get("from"), from)
+		if from == nil || to == nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		lines := diff.Lines(from.Body, to.Body)
+		data["From"] = from
+		data["To"] = to
+		data["Lines"] = lines
+		data["BodyChanged"] = diff.Changed(lines)
+	}
+
+	err = h.templates.ExecuteTemplate(w, "admin_revisions.html", data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// RestoreRevision puts the title and body of the {rev} revision back into
+// the post, which records a new revision.
+func (h *AdminHandler) RestoreRevision(w http.ResponseWriter, r *http.Request) {
+	post := h.getPost(w, r)
+	if post == nil {
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "rev"), 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	rev, err := h.app.Revisions.GetRevision(r.Context(), post.ID, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	post.Title = rev.Title
+	post.Body = rev.Body
+	if err := h.app.UpdatePost(r.Context(), post, editorName(r)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.app.RefreshRelated()
+	http.Redirect(w, r, "/admin/posts/"+strconv.FormatInt(post.ID, 10)+"/revisions", http.StatusSeeOther)
+}
+
+// findRevision returns the revision of revs whose id is param, or def when
+// param is empty. It returns nil for an unknown id.
+func findRevision(revs []database.Revision, param string, def *database.Revision) *database.Revision {
+	if param == "" {
+		return def
+	}
+	id, err := strconv.ParseInt(param, 10, 64)
+	if err != nil {
+		return nil
+	}
+	for i := range revs {
+		if revs[i].ID == id {
+			return &revs[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gochi-demo/internal/app"
+	"github.com/gochi-demo/internal/database"
+	"github.com/gochi-demo/internal/web"
+)
+
+type SearchHandler struct {
+	app       *app.App
+	templates *web.Templates
+}
+
+func NewSearchHandler(app *app.App, templates *web.Templates) *SearchHandler {
+	return &SearchHandler{app: app, templates: templates}
+}
+
+// searchResult is the JSON shape of a search hit. Searches don't load the
+// body of posts, the snippet stands in for it.
+type searchResult struct {
+	ID          int64     `json:"id"`
+	Slug        string    `json:"slug"`
+	Title       string    `json:"title"`
+	Tags        []string  `json:"tags"`
+	PublishedAt time.Time `json:"published_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Author      string    `json:"author"`
+	Status      string    `json:"status"`
+	Rank        float64   `json:"rank"`
+	Snippet     string    `json:"snippet"`
+	SnippetHTML string    `json:"snippet_html"`
+}
+
+// runSearch runs the ?q= query of r for the requested page. It writes the
+// error response itself and returns false when the page can't be served.
+func (h *SearchHandler) runSearch(w http.ResponseWriter, r *http.Request) (string, []database.SearchResult, Pagination, bool) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+
+	total, err := h.app.Search.CountSearch(r.Context(), query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return query, nil, Pagination{}, false
+	}
+
+	pagination, ok := newPagination(r, total, postsPerPage())
+	if !ok {
+		http.NotFound(w, r)
+		return query, nil, pagination, false
+	}
+
+	results, err := h.app.Search.Search(r.Context(), query, pagination.PerPage, pagination.Offset())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return query, nil, pagination, false
+	}
+
+	return query, results, pagination, true
+}
+
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	query, results, pagination, ok := h.runSearch(w, r)
+	if !ok {
+		return
+	}
+
+	data := map[string]any{
+		"Username":   username(r),
+		"Query":      query,
+		"Results":    results,
+		"Pagination": pagination,
+	}
+
+	err := h.templates.ExecuteTemplate(w, "search.html", data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *SearchHandler) SearchJSON(w http.ResponseWriter, r *http.Request) {
+	query, results, pagination, ok := h.runSearch(w, r)
+	if !ok {
+		return
+	}
+
+	hits := make([]searchResult, len(results))
+	for i := range results {
+		res := &results[i]
+		hits[i] = searchResult{
+			ID:          res.ID,
+			Slug:        res.Slug,
+			Title:       res.Title,
+			Tags:        res.Tags,
+			PublishedAt: res.PublishedAt,
+			UpdatedAt:   res.UpdatedAt,
+			Author:      res.Author,
+			Status:      res.Status,
+			Rank:        res.Rank,
+			Snippet:     res.PlainSnippet(),
+			SnippetHTML: string(res.Highlighted()),
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"query":      query,
+		"results":    hits,
+		"pagination": pagination,
+	})
+}
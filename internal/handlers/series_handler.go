@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gochi-demo/internal/app"
+	"github.com/gochi-demo/internal/web"
+)
+
+type SeriesHandler struct {
+	app       *app.App
+	templates *web.Templates
+}
+
+func NewSeriesHandler(app *app.App, templates *web.Templates) *SeriesHandler {
+	return &SeriesHandler{app: app, templates: templates}
+}
+
+// GetSeries lists the published parts of the {slug} series in order.
+func (h *SeriesHandler) GetSeries(w http.ResponseWriter, r *http.Request) {
+	series, err := h.app.Series.GetBySlug(r.Context(), chi.URLParam(r, "slug"))
+	if errors.Is(err, sql.ErrNoRows) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(series.Posts) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	for i := range series.Posts {
+		if _, err := h.app.RenderPost(&series.Posts[i]); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	data := map[string]any{
+		"Username": username(r),
+		"Series":   series,
+	}
+
+	err = h.templates.ExecuteTemplate(w, "series.html", data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gochi-demo/internal/app"
+	"github.com/gochi-demo/internal/config"
+	"github.com/gochi-demo/internal/database"
+	"github.com/gochi-demo/internal/sitemap"
+)
+
+const xmlContentType = "application/xml; charset=utf-8"
+
+type SitemapHandler struct {
+	app *app.App
+}
+
+func NewSitemapHandler(app *app.App) *SitemapHandler {
+	return &SitemapHandler{app: app}
+}
+
+// Sitemap serves the sitemap, or a sitemap index pointing at the numbered
+// parts once the site has more than sitemap.MaxURLs pages.
+func (h *SitemapHandler) Sitemap(w http.ResponseWriter, r *http.Request) {
+	urls, err := h.urls(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	chunks := sitemap.Split(urls)
+	if len(chunks) == 1 {
+		body, err := sitemap.Encode(urls)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		serveConditional(w, r, xmlContentType, sitemap.LastMod(urls), body)
+		return
+	}
+
+	site := config.GetSite()
+	parts := make([]sitemap.URL, len(chunks))
+	for i, chunk := range chunks {
+		parts[i] = sitemap.URL{
+			Loc:     fmt.Sprintf("%s/sitemap-%d.xml", site.URL, i+1),
+			LastMod: sitemap.LastMod(chunk),
+		}
+	}
+
+	body, err := sitemap.EncodeIndex(parts)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	serveConditional(w, r, xmlContentType, sitemap.LastMod(urls), body)
+}
+
+// SitemapPart serves the {n}th part of a sitemap split by Sitemap.
+func (h *SitemapHandler) SitemapPart(w http.ResponseWriter, r *http.Request) {
+	n, err := strconv.Atoi(chi.URLParam(r, "n"))
+	if err != nil || n < 1 {
+		http.NotFound(w, r)
+		return
+	}
+
+	urls, err := h.urls(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	chunks := sitemap.Split(urls)
+	if len(chunks) == 1 || n > len(chunks) {
+		http.NotFound(w, r)
+		return
+	}
+
+	body, err := sitemap.Encode(chunks[n-1])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	serveConditional(w, r, xmlContentType, sitemap.LastMod(chunks[n-1]), body)
+}
+
+// urls lists every public page: the home page, the posts, the tag pages,
+// the archive pages and the series pages.
+func (h *SitemapHandler) urls(r *http.Request) ([]sitemap.URL, error) {
+	posts, err := h.app.Posts.ListIndex(r.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	site := config.GetSite()
+	urls := make([]sitemap.URL, 0, len(posts)+2)
+
+	lastMod := latestUpdate(posts)
+	urls = append(urls, sitemap.URL{Loc: site.URL + "/", LastMod: lastMod})
+
+	for _, p := range posts {
+		urls = append(urls, sitemap.URL{Loc: site.URL + p.URL(), LastMod: p.UpdatedAt})
+	}
+
+	// A tag page changes whenever one of its posts does.
+	tagMod := map[string]sitemap.URL{}
+	var tagNames []string
+	for _, p := range posts {
+		for _, tag := range p.Tags {
+			u, ok := tagMod[tag]
+			if !ok {
+				tagNames = append(tagNames, tag)
+				u.Loc = site.URL + database.TagURL(tag)
+			}
+			if p.UpdatedAt.After(u.LastMod) {
+				u.LastMod = p.UpdatedAt
+			}
+			tagMod[tag] = u
+		}
+	}
+
+	sort.Strings(tagNames)
+	if len(tagNames) > 0 {
+		urls = append(urls, sitemap.URL{Loc: site.URL + "/tags", LastMod: lastMod})
+	}
+	for _, tag := range tagNames {
+		urls = append(urls, tagMod[tag])
+	}
+
+	// So do the archive pages of its year and month.
+	if len(posts) > 0 {
+		urls = append(urls, sitemap.URL{Loc: site.URL + "/archive", LastMod: lastMod})
+	}
+	archiveMod := map[string]time.Time{}
+	var archivePaths []string
+	for _, p := range posts {
+		t := p.PublishedAt.UTC()
+		for _, path := range []string{database.YearURL(t.Year()), database.MonthURL(t.Year(), t.Month())} {
+			mod, ok := archiveMod[path]
+			if !ok {
+				archivePaths = append(archivePaths, path)
+			}
+			if p.UpdatedAt.After(mod) {
+				archiveMod[path] = p.UpdatedAt
+			}
+		}
+	}
+	for _, path := range archivePaths {
+		urls = append(urls, sitemap.URL{Loc: site.URL + path, LastMod: archiveMod[path]})
+	}
+
+	series, err := h.app.Series.List(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	for _, se := range series {
+		urls = append(urls, sitemap.URL{Loc: site.URL + se.URL(), LastMod: se.UpdatedAt})
+	}
+
+	return urls, nil
+}
+
+// Robots serves robots.txt. Crawlers are kept out entirely on staging, or
+// when ROBOTS_DISALLOW_ALL is set, and otherwise pointed at the sitemap.
+// ROBOTS_DISALLOW takes a comma separated list of extra paths to block.
+func (h *SitemapHandler) Robots(w http.ResponseWriter, r *http.Request) {
+	var sb strings.Builder
+	sb.WriteString("User-agent: *\n")
+
+	blockAll := config.Environment() == config.EnvStaging ||
+		strings.EqualFold(config.GetConfig("ROBOTS_DISALLOW_ALL"), "true")
+
+	if blockAll {
+		sb.WriteString("Disallow: /\n")
+	} else {
+		sb.WriteString("Allow: /\n")
+		for _, path := range strings.Split(config.GetConfig("ROBOTS_DISALLOW"), ",") {
+			if path = strings.TrimSpace(path); path != "" {
+				sb.WriteString("Disallow: " + path + "\n")
+			}
+		}
+		sb.WriteString("\nSitemap: " + config.GetSite().URL + "/sitemap.xml\n")
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte(sb.String()))
+}
+
+// latestUpdate returns the most recent UpdatedAt of posts.
+func latestUpdate(posts []database.Post) (last time.Time) {
+	for _, p := range posts {
+		if p.UpdatedAt.After(last) {
+			last = p.UpdatedAt
+		}
+	}
+	return last
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gochi-demo/internal/app"
+	"github.com/gochi-demo/internal/database/dbtest"
+)
+
+func TestSitemap(t *testing.T) {
+	for _, b := range dbtest.Backends(t) {
+		t.Run(b.Name, func(t *testing.T) {
+			a := &app.App{Posts: b.Posts, Series: b.Series}
+			posts := testPosts(t, a)
+			h := NewSitemapHandler(a)
+
+			w := httptest.NewRecorder()
+			h.Sitemap(w, httptest.NewRequest("GET", "/sitemap.xml", nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("status %d: %s", w.Code, w.Body)
+			}
+
+			var doc struct {
+				XMLName xml.Name `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+				URLs    []struct {
+					Loc     string `xml:"loc"`
+					LastMod string `xml:"lastmod"`
+				} `xml:"url"`
+			}
+			if err := xml.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+				t.Fatal(err)
+			}
+			var links []string
+			for _, u := range doc.URLs {
+				if u.LastMod == "" {
+					t.Errorf("%s has no lastmod", u.Loc)
+				}
+				links = append(links, u.Loc)
+			}
+			checkListed(t, links, posts)
+
+			r := httptest.NewRequest("GET", "/sitemap.xml", nil)
+			r.Header.Set("If-None-Match", w.Header().Get("ETag"))
+			w = httptest.NewRecorder()
+			h.Sitemap(w, r)
+			if w.Code != http.StatusNotModified {
+				t.Errorf("revalidation status %d; want %d", w.Code, http.StatusNotModified)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gochi-demo/internal/app"
+	"github.com/gochi-demo/internal/database"
+	"github.com/gochi-demo/internal/web"
+)
+
+type TagHandler struct {
+	app       *app.App
+	templates *web.Templates
+}
+
+func NewTagHandler(app *app.App, templates *web.Templates) *TagHandler {
+	return &TagHandler{app: app, templates: templates}
+}
+
+// tagParam returns the {tag} of r the way tags are stored: unescaped and
+// lower cased, so /tags/Go lists the posts tagged go.
+func tagParam(r *http.Request) string {
+	tag := chi.URLParam(r, "tag")
+	if unescaped, err := url.PathUnescape(tag); err == nil {
+		tag = unescaped
+	}
+	return strings.ToLower(tag)
+}
+
+func (h *TagHandler) ListTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := h.app.Posts.ListTags(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data := map[string]any{
+		"Username": username(r),
+		"Tags":     tags,
+	}
+
+	err = h.templates.ExecuteTemplate(w, "tags.html", data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *TagHandler) GetTag(w http.ResponseWriter, r *http.Request) {
+	tag := tagParam(r)
+
+	count := func(ctx context.Context) (int, error) {
+		return h.app.Posts.CountByTag(ctx, tag)
+	}
+	list := func(ctx context.Context, limit, offset int) ([]database.Post, error) {
+		return h.app.Posts.ListByTag(ctx, tag, limit, offset)
+	}
+
+	posts, pagination, ok := loadPostPage(w, r, h.app, count, list)
+	if !ok {
+		return
+	}
+	if pagination.Total == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	data := map[string]any{
+		"Username":   username(r),
+		"Tag":        tag,
+		"Posts":      posts,
+		"Pagination": pagination,
+	}
+
+	err := h.templates.ExecuteTemplate(w, "tag.html", data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
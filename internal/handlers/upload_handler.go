@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gochi-demo/internal/app"
+	"github.com/gochi-demo/internal/config"
+	"github.com/gochi-demo/internal/imaging"
+)
+
+// defaultMaxUploadBytes is the upload size limit when UPLOAD_MAX_BYTES is
+// not set.
+const defaultMaxUploadBytes = 10 << 20
+
+func maxUploadBytes() int64 {
+	n := config.GetIntConfigWithDefault("UPLOAD_MAX_BYTES", defaultMaxUploadBytes)
+	if n < 1 {
+		return defaultMaxUploadBytes
+	}
+	return int64(n)
+}
+
+// Upload stores the "file" of a multipart form, see App.StoreUpload, and
+// answers with its URL under /media/ and a Markdown snippet embedding it.
+func (h *AdminHandler) Upload(w http.ResponseWriter, r *http.Request) {
+	limit := maxUploadBytes()
+	// Leave some room for the multipart envelope around the file.
+	r.Body = http.MaxBytesReader(w, r.Body, limit+1<<20)
+
+	file, header, err := r.FormFile("file")
+	var tooBig *http.MaxBytesError
+	if errors.As(err, &tooBig) {
+		http.Error(w, "file is too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		http.Error(w, "missing file", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	if header.Size > limit {
+		http.Error(w, "file is too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	contentType := http.DetectContentType(data)
+	if _, ok := app.UploadTypes[contentType]; !ok {
+		http.Error(w, "unsupported file type "+contentType, http.StatusUnsupportedMediaType)
+		return
+	}
+
+	m, err := h.app.StoreUpload(r.Context(), data, contentType, editorName(r))
+	if errors.Is(err, imaging.ErrTooLarge) {
+		http.Error(w, "image is too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if errors.Is(err, app.ErrInvalidUpload) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	name := strings.TrimSuffix(header.Filename, path.Ext(header.Filename))
+	markdown := "[" + name + "](" + m.URL() + ")"
+	if strings.HasPrefix(contentType, "image/") {
+		markdown = "!" + markdown
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]any{
+		"url":      m.URL(),
+		"markdown": markdown,
+		"media":    m,
+	})
+}
@@ -0,0 +1,153 @@
+// Package imaging strips metadata from uploaded images and builds their
+// resized variants.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/webp"
+)
+
+// Widths are the widths of the variants built for an image, when smaller
+// than the image itself.
+var Widths = []int{480, 960, 1600}
+
+// MaxPixels bounds the size of the images Process accepts, so a small file
+// can't make the server allocate gigabytes when decoded.
+const MaxPixels = 50_000_000
+
+// ErrTooLarge is returned for images of more than MaxPixels pixels.
+var ErrTooLarge = errors.New("imaging: image has too many pixels")
+
+// Image is an encoded image along with its dimensions.
+type Image struct {
+	Data        []byte
+	ContentType string
+	Width       int
+	Height      int
+}
+
+// Process returns the image in data without its EXIF, GPS and text
+// metadata, and a smaller copy of it for each of widths narrower than the
+// image. JPEG images are turned upright according to their EXIF
+// orientation. Animated GIFs are kept as they are and get no variants.
+func Process(data []byte, contentType string, widths []int) (*Image, []Image, error) {
+	cfg, _, err := decodeConfig(data, contentType)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cfg.Width*cfg.Height > MaxPixels {
+		return nil, nil, ErrTooLarge
+	}
+
+	if contentType == "image/gif" {
+		if _, err := gif.DecodeAll(bytes.NewReader(data)); err != nil {
+			return nil, nil, err
+		}
+		return &Image{Data: data, ContentType: contentType, Width: cfg.Width, Height: cfg.Height}, nil, nil
+	}
+
+	src, err := decode(data, contentType)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	original := &Image{ContentType: contentType}
+	switch contentType {
+	case "image/jpeg":
+		if o := jpegOrientation(data); o > 1 {
+			// The orientation goes away with the EXIF data, so it has to be
+			// applied to the pixels.
+			src = orient(src, o)
+			original.Data, err = encode(src, contentType)
+		} else {
+			original.Data, err = stripJPEG(data)
+		}
+	case "image/png":
+		original.Data, err = stripPNG(data)
+	case "image/webp":
+		original.Data, err = stripWebP(data)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	original.Width, original.Height = src.Bounds().Dx(), src.Bounds().Dy()
+
+	// WebP can't be written back, its variants are JPEG or PNG.
+	variantType := contentType
+	if variantType == "image/webp" {
+		variantType = "image/png"
+		if opaque(src) {
+			variantType = "image/jpeg"
+		}
+	}
+
+	var variants []Image
+	for _, w := range widths {
+		if w >= original.Width {
+			continue
+		}
+		h := max(1, original.Height*w/original.Width)
+		dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), src, src.Bounds(), draw.Src, nil)
+
+		b, err := encode(dst, variantType)
+		if err != nil {
+			return nil, nil, err
+		}
+		variants = append(variants, Image{Data: b, ContentType: variantType, Width: w, Height: h})
+	}
+
+	return original, variants, nil
+}
+
+func decodeConfig(data []byte, contentType string) (image.Config, string, error) {
+	if contentType == "image/webp" {
+		cfg, err := webp.DecodeConfig(bytes.NewReader(data))
+		return cfg, "webp", err
+	}
+	return image.DecodeConfig(bytes.NewReader(data))
+}
+
+func decode(data []byte, contentType string) (image.Image, error) {
+	r := bytes.NewReader(data)
+	switch contentType {
+	case "image/jpeg":
+		return jpeg.Decode(r)
+	case "image/png":
+		return png.Decode(r)
+	case "image/gif":
+		return gif.Decode(r)
+	case "image/webp":
+		return webp.Decode(r)
+	}
+	return nil, fmt.Errorf("imaging: unsupported content type %q", contentType)
+}
+
+func encode(img image.Image, contentType string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	switch contentType {
+	case "image/jpeg":
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 82})
+	case "image/png":
+		err = png.Encode(&buf, img)
+	default:
+		err = fmt.Errorf("imaging: can't encode %q", contentType)
+	}
+	return buf.Bytes(), err
+}
+
+func opaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return false
+}
@@ -0,0 +1,361 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"testing"
+
+	"golang.org/x/image/webp"
+)
+
+// Markers hidden in the metadata of the fixtures. None may survive
+// stripping.
+const (
+	exifMarker    = "exif-gps-48.8584N-2.2945E"
+	xmpMarker     = "xmp-gps-48.8584N"
+	textMarker    = "png-text-home-address"
+	commentMarker = "jpeg-comment-owner"
+)
+
+const xmpPacket = `<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">` +
+	`<rdf:Description exif:GPSLatitude="` + xmpMarker + `"/></rdf:RDF></x:xmpmeta>`
+
+var allMarkers = []string{exifMarker, xmpMarker, textMarker, commentMarker}
+
+// exifTIFF returns a TIFF structure, as found in EXIF data, holding an
+// Orientation tag, unless o is 0, and an ImageDescription with exifMarker.
+func exifTIFF(order binary.ByteOrder, o int) []byte {
+	var b bytes.Buffer
+	if order == binary.LittleEndian {
+		b.WriteString("II")
+	} else {
+		b.WriteString("MM")
+	}
+	binary.Write(&b, order, uint16(42))
+	binary.Write(&b, order, uint32(8))
+
+	desc := exifMarker + "\x00"
+	entries := 1
+	if o != 0 {
+		entries++
+	}
+	binary.Write(&b, order, uint16(entries))
+	// ImageDescription, ASCII, stored after the IFD.
+	binary.Write(&b, order, uint16(0x010E))
+	binary.Write(&b, order, uint16(2))
+	binary.Write(&b, order, uint32(len(desc)))
+	binary.Write(&b, order, uint32(8+2+12*entries+4))
+	if o != 0 {
+		// Orientation, SHORT, stored in the entry.
+		binary.Write(&b, order, uint16(0x0112))
+		binary.Write(&b, order, uint16(3))
+		binary.Write(&b, order, uint32(1))
+		binary.Write(&b, order, uint16(o))
+		binary.Write(&b, order, uint16(0))
+	}
+	binary.Write(&b, order, uint32(0))
+	b.WriteString(desc)
+	return b.Bytes()
+}
+
+// testImage returns a w×h image whose top left pixel is red and the rest
+// blue, so turning it shows.
+func testImage(w, h int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.NRGBA{B: 255, A: 255})
+		}
+	}
+	img.Set(0, 0, color.NRGBA{R: 255, A: 255})
+	return img
+}
+
+// jpegSegment returns a JPEG marker segment.
+func jpegSegment(marker byte, payload []byte) []byte {
+	seg := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(seg[2:], uint16(len(payload)+2))
+	return append(seg, payload...)
+}
+
+// jpegFixture returns a JPEG with EXIF, XMP and a comment. The EXIF data
+// holds orientation o unless it is 0.
+func jpegFixture(t *testing.T, w, h int, order binary.ByteOrder, o int) []byte {
+	t.Helper()
+	var enc bytes.Buffer
+	if err := jpeg.Encode(&enc, testImage(w, h), &jpeg.Options{Quality: 100}); err != nil {
+		t.Fatal(err)
+	}
+	data := enc.Bytes()
+
+	var b bytes.Buffer
+	b.Write(data[:2])
+	b.Write(jpegSegment(0xE1, append([]byte("Exif\x00\x00"), exifTIFF(order, o)...)))
+	b.Write(jpegSegment(0xE1, []byte("http://ns.adobe.com/xap/1.0/\x00"+xmpPacket)))
+	b.Write(jpegSegment(0xFE, []byte(commentMarker)))
+	b.Write(data[2:])
+	return b.Bytes()
+}
+
+// pngChunk returns a PNG chunk with its CRC.
+func pngChunk(typ string, payload []byte) []byte {
+	c := make([]byte, 8, 12+len(payload))
+	binary.BigEndian.PutUint32(c, uint32(len(payload)))
+	copy(c[4:], typ)
+	c = append(c, payload...)
+	return binary.BigEndian.AppendUint32(c, crc32.ChecksumIEEE(c[4:]))
+}
+
+// pngChunkTypes returns the types of the chunks of a PNG file.
+func pngChunkTypes(data []byte) []string {
+	var types []string
+	for i := 8; i+12 <= len(data); {
+		size := int(binary.BigEndian.Uint32(data[i:]))
+		types = append(types, string(data[i+4:i+8]))
+		i += 12 + size
+	}
+	return types
+}
+
+func assertNoMarkers(t *testing.T, data []byte) {
+	t.Helper()
+	for _, m := range allMarkers {
+		if bytes.Contains(data, []byte(m)) {
+			t.Errorf("stripped image still holds %q", m)
+		}
+	}
+}
+
+func TestStripJPEG(t *testing.T) {
+	in := jpegFixture(t, 8, 4, binary.LittleEndian, 1)
+	for _, m := range []string{exifMarker, xmpMarker, commentMarker} {
+		if !bytes.Contains(in, []byte(m)) {
+			t.Fatalf("fixture lacks %q", m)
+		}
+	}
+
+	out, err := stripJPEG(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertNoMarkers(t, out)
+	if bytes.Contains(out, []byte("Exif\x00\x00")) || bytes.Contains(out, []byte("http://ns.adobe.com/xap/1.0/")) {
+		t.Error("stripped JPEG still has an EXIF or XMP segment")
+	}
+
+	img, err := jpeg.Decode(bytes.NewReader(out))
+	if err != nil {
+		t.Fatalf("stripped JPEG doesn't decode: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 8 || b.Dy() != 4 {
+		t.Errorf("stripped JPEG is %dx%d; want 8x4", b.Dx(), b.Dy())
+	}
+}
+
+func TestStripJPEGMalformed(t *testing.T) {
+	valid := jpegFixture(t, 4, 4, binary.LittleEndian, 0)
+	tests := map[string][]byte{
+		"empty":     nil,
+		"not jpeg":  []byte("\x89PNG\r\n\x1a\nnot a jpeg"),
+		"truncated": valid[:40],
+		"bad size":  {0xFF, 0xD8, 0xFF, 0xE1, 0xFF, 0xFF, 0x00},
+	}
+	for name, data := range tests {
+		if _, err := stripJPEG(data); err == nil {
+			t.Errorf("%s: stripJPEG succeeded", name)
+		}
+	}
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		for o := 1; o <= 8; o++ {
+			if got := jpegOrientation(jpegFixture(t, 2, 2, order, o)); got != o {
+				t.Errorf("%v orientation %d read as %d", order, o, got)
+			}
+		}
+	}
+
+	tests := map[string][]byte{
+		"no orientation tag": jpegFixture(t, 2, 2, binary.LittleEndian, 0),
+		"out of range":       jpegFixture(t, 2, 2, binary.LittleEndian, 9),
+		"not jpeg":           []byte("GIF89a"),
+		"empty":              nil,
+	}
+	for name, data := range tests {
+		if got := jpegOrientation(data); got != 1 {
+			t.Errorf("%s: orientation %d; want 1", name, got)
+		}
+	}
+}
+
+func TestOrient(t *testing.T) {
+	red := color.NRGBA{R: 255, A: 255}
+	tests := []struct {
+		o          int
+		w, h       int
+		redX, redY int
+	}{
+		{1, 3, 2, 0, 0},
+		{2, 3, 2, 2, 0},
+		{3, 3, 2, 2, 1},
+		{4, 3, 2, 0, 1},
+		{5, 2, 3, 0, 0},
+		{6, 2, 3, 1, 0},
+		{7, 2, 3, 1, 2},
+		{8, 2, 3, 0, 2},
+	}
+	for _, tt := range tests {
+		img := orient(testImage(3, 2), tt.o)
+		if b := img.Bounds(); b.Dx() != tt.w || b.Dy() != tt.h {
+			t.Errorf("orientation %d: %dx%d; want %dx%d", tt.o, b.Dx(), b.Dy(), tt.w, tt.h)
+			continue
+		}
+		if c := color.NRGBAModel.Convert(img.At(tt.redX, tt.redY)); c != red {
+			t.Errorf("orientation %d: pixel %d,%d is %v; want red", tt.o, tt.redX, tt.redY, c)
+		}
+	}
+}
+
+func TestProcessOrientedJPEG(t *testing.T) {
+	in := jpegFixture(t, 8, 4, binary.BigEndian, 6)
+	original, _, err := Process(in, "image/jpeg", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertNoMarkers(t, original.Data)
+	if original.Width != 4 || original.Height != 8 {
+		t.Errorf("original is %dx%d; want 4x8", original.Width, original.Height)
+	}
+	img, err := jpeg.Decode(bytes.NewReader(original.Data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 4 || b.Dy() != 8 {
+		t.Errorf("decoded original is %dx%d; want 4x8", b.Dx(), b.Dy())
+	}
+}
+
+func TestStripPNG(t *testing.T) {
+	var enc bytes.Buffer
+	if err := png.Encode(&enc, testImage(5, 3)); err != nil {
+		t.Fatal(err)
+	}
+	data := enc.Bytes()
+
+	// Metadata goes between IHDR, the first chunk, and the image data.
+	ihdrEnd := 8 + 12 + int(binary.BigEndian.Uint32(data[8:]))
+	var b bytes.Buffer
+	b.Write(data[:ihdrEnd])
+	b.Write(pngChunk("eXIf", exifTIFF(binary.BigEndian, 1)))
+	b.Write(pngChunk("tEXt", []byte("Comment\x00"+textMarker)))
+	b.Write(pngChunk("iTXt", []byte("XML:com.adobe.xmp\x00\x00\x00\x00\x00"+xmpPacket)))
+	b.Write(pngChunk("tIME", []byte{0x07, 0xEA, 1, 2, 3, 4, 5}))
+	b.Write(data[ihdrEnd:])
+	in := b.Bytes()
+	if _, err := png.Decode(bytes.NewReader(in)); err != nil {
+		t.Fatalf("fixture doesn't decode: %v", err)
+	}
+
+	out, err := stripPNG(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertNoMarkers(t, out)
+	for _, typ := range pngChunkTypes(out) {
+		if pngMetadata[typ] {
+			t.Errorf("stripped PNG still has a %s chunk", typ)
+		}
+	}
+
+	img, err := png.Decode(bytes.NewReader(out))
+	if err != nil {
+		t.Fatalf("stripped PNG doesn't decode: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 5 || b.Dy() != 3 {
+		t.Errorf("stripped PNG is %dx%d; want 5x3", b.Dx(), b.Dy())
+	}
+
+	if _, err := stripPNG(in[:len(in)-5]); err == nil {
+		t.Error("stripPNG of a truncated PNG succeeded")
+	}
+}
+
+// riffChunk returns a RIFF chunk, padded to an even size.
+func riffChunk(typ string, payload []byte) []byte {
+	c := make([]byte, 8, 9+len(payload))
+	copy(c, typ)
+	binary.LittleEndian.PutUint32(c[4:], uint32(len(payload)))
+	c = append(c, payload...)
+	if len(payload)%2 == 1 {
+		c = append(c, 0)
+	}
+	return c
+}
+
+func TestStripWebP(t *testing.T) {
+	// A simple lossless WebP from golang.org/x/image, holding a single
+	// VP8L chunk.
+	plain, err := os.ReadFile("testdata/gopher.webp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := webp.DecodeConfig(bytes.NewReader(plain))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(plain[12:16]) != "VP8L" {
+		t.Fatalf("fixture starts with a %q chunk; want VP8L", plain[12:16])
+	}
+
+	// The extended format announces EXIF and XMP in its VP8X chunk.
+	vp8x := make([]byte, 10)
+	vp8x[0] = 0x08 | 0x04
+	vp8x[4], vp8x[5], vp8x[6] = byte(cfg.Width-1), byte((cfg.Width-1)>>8), byte((cfg.Width-1)>>16)
+	vp8x[7], vp8x[8], vp8x[9] = byte(cfg.Height-1), byte((cfg.Height-1)>>8), byte((cfg.Height-1)>>16)
+
+	var body bytes.Buffer
+	body.WriteString("WEBP")
+	body.Write(riffChunk("VP8X", vp8x))
+	body.Write(plain[12:])
+	body.Write(riffChunk("EXIF", exifTIFF(binary.LittleEndian, 1)))
+	body.Write(riffChunk("XMP ", []byte(xmpPacket)))
+	in := binary.LittleEndian.AppendUint32([]byte("RIFF"), uint32(body.Len()))
+	in = append(in, body.Bytes()...)
+	if _, err := webp.Decode(bytes.NewReader(in)); err != nil {
+		t.Fatalf("fixture doesn't decode: %v", err)
+	}
+
+	out, err := stripWebP(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertNoMarkers(t, out)
+	if bytes.Contains(out, []byte("EXIF")) || bytes.Contains(out, []byte("XMP ")) {
+		t.Error("stripped WebP still has an EXIF or XMP chunk")
+	}
+	if flags := out[20]; flags&(0x08|0x04) != 0 {
+		t.Errorf("VP8X flags %#x still announce EXIF or XMP", flags)
+	}
+	if size := int(binary.LittleEndian.Uint32(out[4:])); size != len(out)-8 {
+		t.Errorf("RIFF size %d; want %d", size, len(out)-8)
+	}
+
+	img, err := webp.Decode(bytes.NewReader(out))
+	if err != nil {
+		t.Fatalf("stripped WebP doesn't decode: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != cfg.Width || b.Dy() != cfg.Height {
+		t.Errorf("stripped WebP is %dx%d; want %dx%d", b.Dx(), b.Dy(), cfg.Width, cfg.Height)
+	}
+
+	if _, err := stripWebP([]byte("RIFF\x00\x00\x00\x00WAVE")); err == nil {
+		t.Error("stripWebP of a WAVE file succeeded")
+	}
+}
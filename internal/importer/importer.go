@@ -0,0 +1,380 @@
+// Package importer brings in posts from a directory of Markdown files with
+// front matter, as kept by Hugo, Jekyll or Astro blogs.
+package importer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gochi-demo/internal/app"
+	"github.com/gochi-demo/internal/database"
+	"github.com/gochi-demo/internal/render"
+	"gopkg.in/yaml.v3"
+)
+
+// Options tune an import.
+type Options struct {
+	// DryRun reports what would be done without writing anything.
+	DryRun bool
+	// Author is the author of posts whose front matter names none.
+	Author string
+}
+
+// Importer creates or updates a post for every Markdown file of a
+// directory, matching posts by slug so running it again is harmless.
+type Importer struct {
+	app  *app.App
+	opts Options
+
+	// seen maps the slugs imported so far to their file.
+	seen map[string]string
+}
+
+func New(a *app.App, opts Options) *Importer {
+	return &Importer{app: a, opts: opts}
+}
+
+// dateKeys are the front matter fields read as the publish date, in order
+// of preference. Astro themes, AstroPaper included, use pubDatetime.
+var dateKeys = []string{"date", "pubDatetime", "publishDate", "published"}
+
+// keptKeys are the front matter fields left in the post body, where the
+// renderer reads them.
+var keptKeys = []string{"description", "toc"}
+
+// dateLayouts are the date formats accepted in string front matter fields.
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// Run imports the Markdown files found under dir. Files that can't be
+// imported are listed in the report; the error is only set when dir
+// itself can't be walked.
+func (im *Importer) Run(ctx context.Context, dir string) (*Report, error) {
+	report := &Report{Dir: dir, DryRun: im.opts.DryRun}
+	im.seen = map[string]string{}
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		ext := strings.ToLower(filepath.Ext(path))
+		if ext != ".md" && ext != ".markdown" {
+			return nil
+		}
+
+		rel, _ := filepath.Rel(dir, path)
+		entry := Entry{Path: rel}
+		if strings.HasPrefix(d.Name(), "_index.") {
+			// Hugo section pages only list other pages.
+			entry.Action = ActionSkipped
+			entry.Note = "section index"
+		} else if err := im.importFile(ctx, dir, path, &entry); err != nil {
+			entry.Action = ActionFailed
+			entry.Note = err.Error()
+		}
+
+		report.Entries = append(report.Entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// importFile imports the Markdown file at path, found under root, and
+// records what happened in entry.
+func (im *Importer) importFile(ctx context.Context, root, path string, entry *Entry) error {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	meta, body, err := render.SplitFrontMatter(string(src))
+	if err != nil {
+		return err
+	}
+
+	title, _ := meta["title"].(string)
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return errors.New("no title in the front matter")
+	}
+
+	slug, _ := meta["slug"].(string)
+	if slug == "" {
+		slug = fileSlug(path)
+	}
+	if !app.ValidSlug(slug) {
+		slug = app.Slugify(slug)
+	}
+	if slug == "" {
+		return errors.New("no usable slug")
+	}
+	entry.Slug = slug
+	if first, ok := im.seen[slug]; ok {
+		return errors.New("slug already used by " + first)
+	}
+	im.seen[slug] = entry.Path
+
+	draft := truthy(meta["draft"])
+	publishedAt, err := frontMatterDate(meta)
+	if err != nil {
+		return err
+	}
+	// Drafts without a date get one when they are published.
+	if publishedAt.IsZero() && !draft {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		publishedAt = info.ModTime()
+	}
+	publishedAt = publishedAt.UTC().Truncate(time.Second)
+
+	status := database.StatusPublished
+	if draft {
+		status = database.StatusDraft
+	} else if publishedAt.After(time.Now()) {
+		status = database.StatusScheduled
+	}
+
+	author, _ := meta["author"].(string)
+
+	body, images, err := im.rewriteImages(ctx, root, filepath.Dir(path), body, author)
+	if err != nil {
+		return err
+	}
+	entry.Images = images
+
+	body, err = keepFrontMatter(meta, body)
+	if err != nil {
+		return err
+	}
+
+	post, err := im.app.Posts.GetBySlug(ctx, slug)
+	if errors.Is(err, sql.ErrNoRows) {
+		post = nil
+	} else if err != nil {
+		return err
+	}
+
+	if post == nil {
+		entry.Action = ActionCreated
+		post = &database.Post{Slug: slug, Author: im.opts.Author}
+	} else {
+		entry.Action = ActionUpdated
+	}
+	if author != "" {
+		post.Author = author
+	}
+
+	// Stored tags come back sorted.
+	tags := frontMatterTags(meta["tags"])
+	sortedTags := slices.Sorted(slices.Values(tags))
+	if entry.Action == ActionUpdated &&
+		post.Title == title && post.Body == body && post.Status == status &&
+		post.PublishedAt.Equal(publishedAt) && slices.Equal(post.Tags, sortedTags) {
+		entry.Action = ActionUnchanged
+		return nil
+	}
+
+	post.Title = title
+	post.Body = body
+	post.Tags = tags
+	post.Status = status
+	post.PublishedAt = publishedAt
+
+	if im.opts.DryRun {
+		return nil
+	}
+	if entry.Action == ActionCreated {
+		return im.app.CreatePost(ctx, post, "importer")
+	}
+	return im.app.UpdatePost(ctx, post, "importer")
+}
+
+var (
+	markdownImage = regexp.MustCompile(`(!\[[^\]]*\]\(\s*<?)([^)\s>]+)`)
+	htmlImage     = regexp.MustCompile(`(<img\s[^>]*\bsrc=["'])([^"']+)`)
+)
+
+// rewriteImages stores the local images linked from body in the blob
+// store and points the links at the stored copies. Relative links are
+// resolved against dir, the directory of the post, and links starting
+// with "/" against root. It returns the number of images rewritten.
+// Remote and missing images are left alone.
+func (im *Importer) rewriteImages(ctx context.Context, root, dir, body, uploadedBy string) (string, int, error) {
+	if uploadedBy == "" {
+		uploadedBy = im.opts.Author
+	}
+
+	count := 0
+	var firstErr error
+	rewrite := func(pattern *regexp.Regexp) {
+		body = pattern.ReplaceAllStringFunc(body, func(match string) string {
+			m := pattern.FindStringSubmatch(match)
+			prefix, link := m[1], m[2]
+
+			path := localPath(root, dir, link)
+			if path == "" {
+				return match
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return match
+			}
+
+			contentType := http.DetectContentType(data)
+			if _, ok := app.UploadTypes[contentType]; !ok || !strings.HasPrefix(contentType, "image/") {
+				return match
+			}
+
+			var key string
+			if im.opts.DryRun {
+				key, err = app.ImportKey(data, contentType)
+			} else {
+				var media *database.Media
+				if media, err = im.app.StoreImport(ctx, data, contentType, uploadedBy); err == nil {
+					key = media.Key
+				}
+			}
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("image %s: %w", link, err)
+				}
+				return match
+			}
+
+			count++
+			return prefix + database.MediaURL(key)
+		})
+	}
+	rewrite(markdownImage)
+	rewrite(htmlImage)
+
+	return body, count, firstErr
+}
+
+// localPath returns the file an image link points at, or "" for links to
+// other sites and to files outside of root, which a post must not be able
+// to read.
+func localPath(root, dir, link string) string {
+	u, err := url.Parse(link)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" {
+		return ""
+	}
+	path := filepath.Join(dir, filepath.FromSlash(u.Path))
+	if strings.HasPrefix(u.Path, "/") {
+		path = filepath.Join(root, filepath.FromSlash(u.Path))
+	}
+	if rel, err := filepath.Rel(root, path); err != nil || !filepath.IsLocal(rel) {
+		return ""
+	}
+	return path
+}
+
+// fileSlug derives a slug from the name of a post file. Page bundles,
+// where the post is the index of its own directory, are named after the
+// directory.
+func fileSlug(path string) string {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if name == "index" {
+		name = filepath.Base(filepath.Dir(path))
+	}
+	// Jekyll posts start with their date.
+	if len(name) > 11 && name[4] == '-' && name[7] == '-' && name[10] == '-' {
+		if _, err := time.Parse("2006-01-02", name[:10]); err == nil {
+			name = name[11:]
+		}
+	}
+	return name
+}
+
+// frontMatterDate returns the first date found under dateKeys, or the
+// zero time when there is none.
+func frontMatterDate(meta map[string]any) (time.Time, error) {
+	for _, key := range dateKeys {
+		switch v := meta[key].(type) {
+		case time.Time:
+			return v, nil
+		case string:
+			for _, layout := range dateLayouts {
+				if t, err := time.Parse(layout, strings.TrimSpace(v)); err == nil {
+					return t, nil
+				}
+			}
+			return time.Time{}, fmt.Errorf("unreadable %s %q", key, v)
+		}
+	}
+	return time.Time{}, nil
+}
+
+// frontMatterTags reads a list of tags, or a comma separated string.
+func frontMatterTags(v any) []string {
+	var tags []string
+	switch v := v.(type) {
+	case string:
+		tags = strings.Split(v, ",")
+	case []any:
+		for _, t := range v {
+			if s, ok := t.(string); ok {
+				tags = append(tags, s)
+			}
+		}
+	}
+	return database.CleanTags(tags)
+}
+
+func truthy(v any) bool {
+	switch v := v.(type) {
+	case bool:
+		return v
+	case string:
+		return strings.EqualFold(v, "true") || v == "yes"
+	}
+	return false
+}
+
+// keepFrontMatter puts the keptKeys of meta back in front of body, as
+// YAML front matter.
+func keepFrontMatter(meta map[string]any, body string) (string, error) {
+	kept := map[string]any{}
+	for _, key := range keptKeys {
+		if v, ok := meta[key]; ok {
+			kept[key] = v
+		}
+	}
+	body = strings.TrimLeft(body, "\n")
+	if len(kept) == 0 {
+		return body, nil
+	}
+
+	header, err := yaml.Marshal(kept)
+	if err != nil {
+		return "", err
+	}
+	return "---\n" + string(header) + "---\n" + body, nil
+}
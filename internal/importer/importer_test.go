@@ -0,0 +1,102 @@
+package importer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gochi-demo/internal/app"
+	"github.com/gochi-demo/internal/database/dbtest"
+)
+
+func TestLocalPath(t *testing.T) {
+	root := filepath.FromSlash("/site/content")
+	dir := filepath.Join(root, "posts", "hello")
+
+	tests := []struct {
+		link string
+		want string
+	}{
+		{"cover.png", "posts/hello/cover.png"},
+		{"./img/cover.png", "posts/hello/img/cover.png"},
+		{"../shared/cover.png", "posts/shared/cover.png"},
+		{"/images/cover.png", "images/cover.png"},
+		{"cover.png?v=2#top", "posts/hello/cover.png"},
+		{"../../../secret.png", ""},
+		{"../../../../etc/passwd", ""},
+		{"/../secret.png", ""},
+		{"/../../etc/passwd", ""},
+		{"https://example.com/cover.png", ""},
+		{"//example.com/cover.png", ""},
+		{"#top", ""},
+	}
+	for _, tt := range tests {
+		want := tt.want
+		if want != "" {
+			want = filepath.Join(root, filepath.FromSlash(want))
+		}
+		if got := localPath(root, dir, tt.link); got != want {
+			t.Errorf("localPath(%q) = %q, want %q", tt.link, got, want)
+		}
+	}
+}
+
+func TestImportDateFallback(t *testing.T) {
+	ctx := context.Background()
+	modTime := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+
+	for _, b := range dbtest.Backends(t) {
+		t.Run(b.Name, func(t *testing.T) {
+			tests := []struct {
+				name  string
+				draft bool
+				want  time.Time
+			}{
+				{"post", false, modTime},
+				{"draft", true, time.Time{}},
+			}
+
+			dir := t.TempDir()
+			slugs := map[string]string{}
+			for _, tt := range tests {
+				slug := strings.ToLower(strings.NewReplacer("/", "-", "_", "-").Replace(dbtest.ID(t) + "-" + tt.name))
+				slugs[tt.name] = slug
+				src := "---\ntitle: " + tt.name + "\nslug: " + slug + "\n"
+				if tt.draft {
+					src += "draft: true\n"
+				}
+				src += "---\n\nNo date in the front matter.\n"
+				path := filepath.Join(dir, tt.name+".md")
+				if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.Chtimes(path, modTime, modTime); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			a := &app.App{Posts: b.Posts}
+			report, err := New(a, Options{}).Run(ctx, dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if report.Failed() {
+				t.Fatalf("import failed: %+v", report)
+			}
+
+			for _, tt := range tests {
+				p, err := b.Posts.GetBySlug(ctx, slugs[tt.name])
+				if err != nil {
+					t.Fatal(err)
+				}
+				t.Cleanup(func() { b.Posts.Delete(ctx, p.ID) })
+				if !p.PublishedAt.Equal(tt.want) {
+					t.Errorf("%s: PublishedAt = %v; want %v", tt.name, p.PublishedAt, tt.want)
+				}
+			}
+		})
+	}
+}
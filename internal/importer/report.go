@@ -0,0 +1,83 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+)
+
+// What happened to an imported file.
+const (
+	ActionCreated   = "created"
+	ActionUpdated   = "updated"
+	ActionUnchanged = "unchanged"
+	ActionSkipped   = "skipped"
+	ActionFailed    = "failed"
+)
+
+// Entry is the outcome of importing one file.
+type Entry struct {
+	// Path is relative to the imported directory.
+	Path   string
+	Slug   string
+	Action string
+	// Images is the number of image links pointed at the blob store.
+	Images int
+	// Note explains skipped and failed files.
+	Note string
+}
+
+// Report lists the outcome of every file of an import.
+type Report struct {
+	Dir     string
+	DryRun  bool
+	Entries []Entry
+}
+
+// Count returns the number of files that ended with action.
+func (r *Report) Count(action string) int {
+	n := 0
+	for _, e := range r.Entries {
+		if e.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// Failed reports whether any file could not be imported.
+func (r *Report) Failed() bool {
+	return r.Count(ActionFailed) > 0
+}
+
+// Write prints a line per file followed by the totals.
+func (r *Report) Write(w io.Writer) {
+	for _, e := range r.Entries {
+		line := fmt.Sprintf("%-9s  %s", e.Action, e.Path)
+		if e.Slug != "" {
+			line += " -> /posts/" + e.Slug
+		}
+		if e.Images > 0 {
+			line += fmt.Sprintf(" (%d images)", e.Images)
+		}
+		if e.Note != "" {
+			line += ": " + e.Note
+		}
+		fmt.Fprintln(w, line)
+	}
+
+	images := 0
+	for _, e := range r.Entries {
+		images += e.Images
+	}
+
+	fmt.Fprintln(w)
+	if r.DryRun {
+		fmt.Fprintf(w, "Dry run of the import of %s, nothing was written.\n", r.Dir)
+	} else {
+		fmt.Fprintf(w, "Imported %s.\n", r.Dir)
+	}
+	for _, action := range []string{ActionCreated, ActionUpdated, ActionUnchanged, ActionSkipped, ActionFailed} {
+		fmt.Fprintf(w, "  %-9s  %d\n", action, r.Count(action))
+	}
+	fmt.Fprintf(w, "  %-9s  %d\n", "images", images)
+}
@@ -0,0 +1,159 @@
+// Package related ranks posts by how much they have in common, from their
+// shared tags and the TF-IDF cosine similarity of their text.
+package related
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// tagWeight is how much having exactly the same tags counts for, against
+// a text similarity between 0 and 1.
+const tagWeight = 0.5
+
+// minScore drops matches that have next to nothing in common.
+const minScore = 0.05
+
+// Doc is a post as seen by Compute.
+type Doc struct {
+	ID   int64
+	Tags []string
+	Text string
+}
+
+// Match is a post related to another one, with its score.
+type Match struct {
+	ID    int64
+	Score float64
+}
+
+// Compute returns, for every doc, up to limit other docs ranked by
+// similarity, best first. Docs with nothing in common get no matches.
+func Compute(docs []Doc, limit int) map[int64][]Match {
+	vectors := tfidf(docs)
+
+	result := make(map[int64][]Match, len(docs))
+	for i := range docs {
+		var matches []Match
+		for j := range docs {
+			if i == j {
+				continue
+			}
+			score := cosine(vectors[i], vectors[j]) + tagWeight*jaccard(docs[i].Tags, docs[j].Tags)
+			if score >= minScore {
+				matches = append(matches, Match{ID: docs[j].ID, Score: score})
+			}
+		}
+
+		sort.Slice(matches, func(a, b int) bool {
+			if matches[a].Score != matches[b].Score {
+				return matches[a].Score > matches[b].Score
+			}
+			return matches[a].ID > matches[b].ID
+		})
+		if len(matches) > limit {
+			matches = matches[:limit]
+		}
+		result[docs[i].ID] = matches
+	}
+	return result
+}
+
+// vector is a sparse, normalized term weight vector.
+type vector map[string]float64
+
+// tfidf weighs the terms of every doc by their frequency in the doc and
+// their rarity across docs.
+func tfidf(docs []Doc) []vector {
+	counts := make([]map[string]int, len(docs))
+	df := map[string]int{}
+	for i, d := range docs {
+		counts[i] = map[string]int{}
+		for _, term := range terms(d.Text) {
+			if counts[i][term] == 0 {
+				df[term]++
+			}
+			counts[i][term]++
+		}
+	}
+
+	n := float64(len(docs))
+	vectors := make([]vector, len(docs))
+	for i, c := range counts {
+		v := make(vector, len(c))
+		var norm float64
+		for term, tf := range c {
+			w := (1 + math.Log(float64(tf))) * math.Log(1+n/float64(df[term]))
+			v[term] = w
+			norm += w * w
+		}
+		norm = math.Sqrt(norm)
+		for term := range v {
+			v[term] /= norm
+		}
+		vectors[i] = v
+	}
+	return vectors
+}
+
+func cosine(a, b vector) float64 {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	var dot float64
+	for term, w := range a {
+		dot += w * b[term]
+	}
+	return dot
+}
+
+// jaccard is the share of tags two docs have in common.
+func jaccard(a, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	set := make(map[string]bool, len(a))
+	for _, t := range a {
+		set[t] = true
+	}
+	shared := 0
+	for _, t := range b {
+		if set[t] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// terms splits text into lowercase words, leaving out stop words and
+// words shorter than three letters.
+func terms(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := words[:0]
+	for _, w := range words {
+		if len([]rune(w)) >= 3 && !stopWords[w] {
+			terms = append(terms, w)
+		}
+	}
+	return terms
+}
+
+var stopWords = map[string]bool{}
+
+func init() {
+	for _, w := range strings.Fields(`
+		about after all also and any are because been before being but can
+		could did does doing each for from further had has have having her
+		here hers him his how into its itself just more most not now off once
+		only other our ours out over own same she should some such than that
+		the their theirs them then there these they this those through too
+		under until very was were what when where which while who whom why
+		will with would you your yours`) {
+		stopWords[w] = true
+	}
+}
@@ -0,0 +1,62 @@
+package related
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompute(t *testing.T) {
+	docs := []Doc{
+		{ID: 1, Tags: []string{"go", "concurrency"}, Text: "Goroutines and channels make concurrency in Go simple."},
+		{ID: 2, Tags: []string{"go", "concurrency"}, Text: "Channels let goroutines share memory by communicating."},
+		{ID: 3, Tags: []string{"go"}, Text: "Generics arrived in Go with type parameters."},
+		{ID: 4, Tags: []string{"cooking"}, Text: "Bake the bread until golden brown."},
+		{ID: 5, Tags: []string{"baking"}, Text: "Bread dough needs time to rise before you bake it."},
+	}
+
+	got := Compute(docs, 5)
+
+	ids := func(matches []Match) []int64 {
+		var ids []int64
+		for _, m := range matches {
+			ids = append(ids, m.ID)
+		}
+		return ids
+	}
+	tests := []struct {
+		id   int64
+		want []int64
+	}{
+		{1, []int64{2, 3}},
+		{2, []int64{1, 3}},
+		{4, []int64{5}},
+	}
+	for _, tt := range tests {
+		if ids := ids(got[tt.id]); !reflect.DeepEqual(ids, tt.want) {
+			t.Errorf("matches of %d = %v; want %v", tt.id, ids, tt.want)
+		}
+	}
+
+	for id, matches := range got {
+		for i, m := range matches {
+			if m.ID == id {
+				t.Errorf("%d is related to itself", id)
+			}
+			if i > 0 && m.Score > matches[i-1].Score {
+				t.Errorf("matches of %d are not sorted: %v", id, matches)
+			}
+		}
+	}
+
+	if limited := Compute(docs, 1); len(limited[1]) != 1 || limited[1][0].ID != 2 {
+		t.Errorf("matches of 1 with a limit of 1 = %v; want only 2", limited[1])
+	}
+}
+
+func TestTerms(t *testing.T) {
+	got := terms("The Go team's new GC, in 2024: faster than ever!")
+	want := []string{"team", "new", "2024", "faster", "ever"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("terms = %q; want %q", got, want)
+	}
+}
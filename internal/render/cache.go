@@ -0,0 +1,34 @@
+package render
+
+import (
+	"crypto/sha256"
+	"sync"
+)
+
+// maxCachedDocuments bounds the cache. It is simply emptied once full,
+// which is good enough for a blog where the hot set is the latest posts.
+const maxCachedDocuments = 1024
+
+// docCache keeps rendered documents keyed by a hash of their source, so
+// listings don't render every post body again on each request.
+type docCache struct {
+	mu   sync.Mutex
+	docs map[[sha256.Size]byte]*Document
+}
+
+var cache = &docCache{docs: map[[sha256.Size]byte]*Document{}}
+
+func (c *docCache) get(src string) *Document {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.docs[sha256.Sum256([]byte(src))]
+}
+
+func (c *docCache) put(src string, doc *Document) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.docs) >= maxCachedDocuments {
+		c.docs = map[[sha256.Size]byte]*Document{}
+	}
+	c.docs[sha256.Sum256([]byte(src))] = doc
+}
@@ -0,0 +1,40 @@
+package render
+
+import (
+	"bytes"
+	"html/template"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// commentMD knows plain CommonMark plus autolinks. Raw HTML is dropped by
+// the renderer before the sanitizer even sees it.
+var commentMD = goldmark.New(goldmark.WithExtensions(extension.Linkify))
+
+var commentPolicy = newCommentPolicy()
+
+// newCommentPolicy allows the subset of Markdown readers get in comments:
+// paragraphs, emphasis, links, code, quotes and lists. Headings, images and
+// tables are reduced to their text.
+func newCommentPolicy() *bluemonday.Policy {
+	p := bluemonday.NewPolicy()
+	p.AllowElements("p", "br", "em", "strong", "del", "code", "pre", "blockquote", "ul", "ol", "li")
+	p.AllowAttrs("href").OnElements("a")
+	p.AllowStandardURLs()
+	p.RequireParseableURLs(true)
+	p.RequireNoFollowOnLinks(true)
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+	return p
+}
+
+// Comment renders the Markdown of a reader comment into strictly sanitized
+// HTML.
+func Comment(src string) (template.HTML, error) {
+	var buf bytes.Buffer
+	if err := commentMD.Convert([]byte(src), &buf); err != nil {
+		return "", err
+	}
+	return template.HTML(commentPolicy.SanitizeBytes(buf.Bytes())), nil
+}
@@ -0,0 +1,66 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Front matter is YAML between "---" lines or, as Hugo also writes it,
+// TOML between "+++" lines.
+const (
+	yamlDelim = "---"
+	tomlDelim = "+++"
+)
+
+// SplitFrontMatter separates a leading YAML or TOML front matter block
+// from the Markdown body. Sources without front matter are returned
+// unchanged.
+func SplitFrontMatter(src string) (map[string]any, string, error) {
+	meta := map[string]any{}
+
+	src = strings.TrimPrefix(src, "\ufeff")
+	normalized := strings.ReplaceAll(src, "\r\n", "\n")
+	var delim string
+	switch {
+	case strings.HasPrefix(normalized, yamlDelim+"\n"):
+		delim = yamlDelim
+	case strings.HasPrefix(normalized, tomlDelim+"\n"):
+		delim = tomlDelim
+	default:
+		return meta, src, nil
+	}
+
+	// Look for the closing delimiter on a line of its own.
+	lines := strings.SplitAfter(normalized, "\n")
+	end := -1
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimRight(lines[i], "\n") == delim {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return meta, src, nil
+	}
+
+	header := strings.Join(lines[1:end], "")
+	body := strings.Join(lines[end+1:], "")
+
+	var err error
+	if delim == tomlDelim {
+		err = toml.Unmarshal([]byte(header), &meta)
+	} else {
+		err = yaml.Unmarshal([]byte(header), &meta)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid front matter: %w", err)
+	}
+	if meta == nil {
+		meta = map[string]any{}
+	}
+
+	return meta, body, nil
+}
@@ -0,0 +1,275 @@
+// Package render turns Markdown post bodies into sanitized HTML.
+package render
+
+import (
+	"bytes"
+	"html/template"
+	"regexp"
+	"strings"
+
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	highlighting "github.com/yuin/goldmark-highlighting/v2"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+)
+
+// Heading is one entry of a document's table of contents.
+type Heading struct {
+	Level int
+	ID    string
+	Text  string
+}
+
+// Document is the result of rendering a Markdown source.
+type Document struct {
+	// Meta holds the front matter, empty when the source has none.
+	Meta map[string]any
+	HTML template.HTML
+	TOC  []Heading
+	// WordCount is the number of words in the rendered text, code blocks
+	// excluded.
+	WordCount int
+	// Summary is the "description" front matter field, or else the start
+	// of the first paragraph.
+	Summary string
+	// Text is the rendered text, one block per line, as search indexes
+	// want it.
+	Text string
+}
+
+// summaryLength is the maximum length, in runes, of a generated summary.
+const summaryLength = 200
+
+var md = goldmark.New(
+	goldmark.WithExtensions(
+		extension.GFM,
+		highlighting.NewHighlighting(
+			highlighting.WithFormatOptions(chromahtml.WithClasses(true)),
+		),
+	),
+	goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+	// Raw HTML is let through here and cleaned up by the sanitizer.
+	goldmark.WithRendererOptions(html.WithUnsafe()),
+)
+
+var policy = newPolicy()
+
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	// Posts are written by our own authors, their links should be followed.
+	p.RequireNoFollowOnLinks(false)
+	// Syntax highlighting and heading anchors rely on classes and ids.
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^[a-zA-Z0-9 _-]+$`)).OnElements("pre", "code", "span", "a", "div")
+	p.AllowAttrs("id").Matching(regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)).OnElements("h1", "h2", "h3", "h4", "h5", "h6")
+	return p
+}
+
+// Markdown renders src, which may start with a YAML front matter block,
+// into sanitized HTML along with its table of contents. Results are cached,
+// the returned Document must not be modified.
+func Markdown(src string) (*Document, error) {
+	if doc := cache.get(src); doc != nil {
+		return doc, nil
+	}
+
+	doc, err := markdown(src)
+	if err != nil {
+		return nil, err
+	}
+
+	cache.put(src, doc)
+	return doc, nil
+}
+
+func markdown(src string) (*Document, error) {
+	meta, body, err := SplitFrontMatter(src)
+	if err != nil {
+		return nil, err
+	}
+
+	source := []byte(body)
+	doc := md.Parser().Parse(text.NewReader(source))
+	// Taken before the heading anchors are added.
+	plain := documentText(doc, source)
+	toc := decorateHeadings(doc, source)
+
+	var buf bytes.Buffer
+	if err := md.Renderer().Render(&buf, source, doc); err != nil {
+		return nil, err
+	}
+
+	// A post can opt out of the table of contents with "toc: false".
+	if show, ok := meta["toc"].(bool); ok && !show {
+		toc = nil
+	}
+
+	summary, _ := meta["description"].(string)
+	if summary == "" {
+		summary = firstParagraph(doc, source)
+	}
+
+	return &Document{
+		Meta:      meta,
+		HTML:      template.HTML(policy.SanitizeBytes(buf.Bytes())),
+		TOC:       toc,
+		WordCount: countWords(doc, source),
+		Summary:   summary,
+		Text:      plain,
+	}, nil
+}
+
+// Text returns the rendered text of src, without markup, link targets,
+// raw HTML or front matter.
+func Text(src string) (string, error) {
+	doc, err := Markdown(src)
+	if err != nil {
+		return "", err
+	}
+	return doc.Text, nil
+}
+
+// decorateHeadings collects the headings below the post title into a
+// table of contents and appends a "#" anchor link to each of them.
+func decorateHeadings(doc ast.Node, source []byte) []Heading {
+	var toc []Heading
+
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		h, ok := n.(*ast.Heading)
+		if !ok || !entering {
+			return ast.WalkContinue, nil
+		}
+
+		id, ok := h.AttributeString("id")
+		if !ok {
+			return ast.WalkSkipChildren, nil
+		}
+		idStr := string(id.([]byte))
+
+		if h.Level > 1 {
+			toc = append(toc, Heading{Level: h.Level, ID: idStr, Text: plainText(h, source)})
+		}
+
+		anchor := ast.NewLink()
+		anchor.Destination = []byte("#" + idStr)
+		anchor.SetAttributeString("class", []byte("heading-anchor"))
+		anchor.AppendChild(anchor, ast.NewString([]byte("#")))
+		h.AppendChild(h, ast.NewString([]byte(" ")))
+		h.AppendChild(h, anchor)
+
+		return ast.WalkSkipChildren, nil
+	})
+
+	return toc
+}
+
+// countWords counts the words of the text nodes below n. Code blocks are
+// skipped since nobody reads them at prose speed.
+func countWords(n ast.Node, source []byte) int {
+	count := 0
+	ast.Walk(n, func(c ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch t := c.(type) {
+		case *ast.FencedCodeBlock, *ast.CodeBlock:
+			return ast.WalkSkipChildren, nil
+		case *ast.Link:
+			if cls, ok := t.AttributeString("class"); ok && string(cls.([]byte)) == "heading-anchor" {
+				return ast.WalkSkipChildren, nil
+			}
+		case *ast.Text:
+			count += len(strings.Fields(string(t.Value(source))))
+		}
+		return ast.WalkContinue, nil
+	})
+	return count
+}
+
+// firstParagraph returns the text of the first top level paragraph,
+// shortened to summaryLength runes on a word boundary.
+func firstParagraph(doc ast.Node, source []byte) string {
+	for c := doc.FirstChild(); c != nil; c = c.NextSibling() {
+		if _, ok := c.(*ast.Paragraph); !ok {
+			continue
+		}
+
+		text := []rune(plainText(c, source))
+		if len(text) <= summaryLength {
+			return string(text)
+		}
+
+		cut := string(text[:summaryLength])
+		if i := strings.LastIndex(cut, " "); i > 0 {
+			cut = cut[:i]
+		}
+		return strings.TrimRight(cut, ",.;:") + "…"
+	}
+	return ""
+}
+
+// documentText returns the text of doc with a line per block. Code is
+// kept, since readers search for it, but raw HTML isn't.
+func documentText(doc ast.Node, source []byte) string {
+	var sb strings.Builder
+	endLine := func() {
+		if sb.Len() > 0 && !strings.HasSuffix(sb.String(), "\n") {
+			sb.WriteByte('\n')
+		}
+	}
+	ast.Walk(doc, func(c ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			if c.Type() == ast.TypeBlock {
+				endLine()
+			}
+			return ast.WalkContinue, nil
+		}
+		switch t := c.(type) {
+		case *ast.FencedCodeBlock, *ast.CodeBlock:
+			lines := t.Lines()
+			for i := 0; i < lines.Len(); i++ {
+				seg := lines.At(i)
+				sb.Write(seg.Value(source))
+			}
+			return ast.WalkSkipChildren, nil
+		case *ast.HTMLBlock, *ast.RawHTML:
+			return ast.WalkSkipChildren, nil
+		case *ast.AutoLink:
+			sb.Write(t.Label(source))
+		case *ast.Text:
+			sb.Write(t.Value(source))
+			if t.SoftLineBreak() || t.HardLineBreak() {
+				sb.WriteByte(' ')
+			}
+		case *ast.String:
+			sb.Write(t.Value)
+		}
+		return ast.WalkContinue, nil
+	})
+	return strings.TrimSpace(sb.String())
+}
+
+// plainText concatenates the text found below n, dropping any markup.
+func plainText(n ast.Node, source []byte) string {
+	var sb strings.Builder
+	ast.Walk(n, func(c ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch t := c.(type) {
+		case *ast.Text:
+			sb.Write(t.Value(source))
+			if t.SoftLineBreak() || t.HardLineBreak() {
+				sb.WriteByte(' ')
+			}
+		case *ast.String:
+			sb.Write(t.Value)
+		}
+		return ast.WalkContinue, nil
+	})
+	return strings.TrimSpace(sb.String())
+}
@@ -0,0 +1,141 @@
+package render
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestMarkdownSanitizes(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		absent  string
+		present string
+	}{
+		{"script", "Hi\n\n<script>alert(1)</script>\n", "<script", "Hi"},
+		{"inline script", "Hi <script>alert(1)</script> there", "alert(1)", "there"},
+		{"javascript link", "[click](javascript:alert(1))", "javascript:", "click"},
+		{"event handler", `<p onclick="alert(1)">text</p>`, "onclick", "text"},
+		{"iframe", `<iframe src="https://example.com"></iframe>`, "<iframe", ""},
+		{"heading id", "## Getting started", "", `id="getting-started"`},
+		{"highlighting classes", "```go\nfunc main() {}\n```", "", `class="chroma"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Markdown(tt.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			html := string(doc.HTML)
+			if tt.absent != "" && strings.Contains(html, tt.absent) {
+				t.Errorf("HTML %q contains %q", html, tt.absent)
+			}
+			if !strings.Contains(html, tt.present) {
+				t.Errorf("HTML %q lacks %q", html, tt.present)
+			}
+		})
+	}
+}
+
+func TestMarkdownTOC(t *testing.T) {
+	src := "# Title\n\n## Getting started\n\nText.\n\n### Install `it`\n\nMore.\n"
+
+	doc, err := Markdown(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Heading{
+		{Level: 2, ID: "getting-started", Text: "Getting started"},
+		{Level: 3, ID: "install-it", Text: "Install it"},
+	}
+	if !reflect.DeepEqual(doc.TOC, want) {
+		t.Errorf("TOC = %+v; want %+v", doc.TOC, want)
+	}
+	if !strings.Contains(string(doc.HTML), `<a href="#getting-started" class="heading-anchor">#</a>`) {
+		t.Errorf("HTML %q lacks the heading anchor", doc.HTML)
+	}
+
+	doc, err = Markdown("---\ntoc: false\n---\n" + src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.TOC != nil {
+		t.Errorf("TOC with toc: false = %+v; want none", doc.TOC)
+	}
+	if !strings.Contains(string(doc.HTML), `id="getting-started"`) {
+		t.Errorf("toc: false dropped the heading ids from %q", doc.HTML)
+	}
+}
+
+func TestMarkdownWordCount(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want int
+	}{
+		{"prose", "One two three.\n\nFour *five* six.", 6},
+		{"fenced code", "One two.\n\n```go\nfunc main() { fmt.Println(\"not counted\") }\n```\n", 2},
+		{"indented code", "One two.\n\n    not counted either\n", 2},
+		{"heading anchor", "## Three word heading", 3},
+		{"front matter", "---\ntitle: Not counted\n---\nOne.", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Markdown(tt.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if doc.WordCount != tt.want {
+				t.Errorf("WordCount = %d; want %d", doc.WordCount, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkdownSummary(t *testing.T) {
+	long := strings.Repeat("lorem ipsum, ", 30)
+
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"first paragraph", "## Intro\n\nFirst *para*.\n\nSecond.", "First para."},
+		{"description", "---\ndescription: From the front matter.\n---\nFirst.", "From the front matter."},
+		{"no paragraph", "## Only a heading", ""},
+		{"cut on a word", long, strings.TrimSuffix(strings.Repeat("lorem ipsum, ", 15), ", ") + "…"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Markdown(tt.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if doc.Summary != tt.want {
+				t.Errorf("Summary = %q; want %q", doc.Summary, tt.want)
+			}
+			if n := utf8.RuneCountInString(doc.Summary); n > summaryLength+1 {
+				t.Errorf("Summary is %d runes long", n)
+			}
+		})
+	}
+}
+
+func TestText(t *testing.T) {
+	src := "---\ntitle: Skipped\n---\n## Hello *world*\n\n" +
+		"See [the docs](https://example.com/docs) and <https://go.dev>.\n\n" +
+		"<div>raw html</div>\n\n" +
+		"```go\nfmt.Println(\"hi\")\n```\n\n" +
+		"- one\n- two\n"
+
+	got, err := Text(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Hello world\nSee the docs and https://go.dev.\nfmt.Println(\"hi\")\none\ntwo"
+	if got != want {
+		t.Errorf("Text = %q; want %q", got, want)
+	}
+}
@@ -0,0 +1,86 @@
+// Package sitemap builds sitemaps.org documents.
+package sitemap
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// MaxURLs is the most URLs a single sitemap may list. Bigger sites have
+// to be split into several sitemaps referenced from a sitemap index.
+const MaxURLs = 50000
+
+const namespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+type URL struct {
+	// Loc is the absolute URL of the page.
+	Loc     string
+	LastMod time.Time
+}
+
+type urlset struct {
+	XMLName xml.Name `xml:"urlset"`
+	XMLNS   string   `xml:"xmlns,attr"`
+	URLs    []entry  `xml:"url"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name `xml:"sitemapindex"`
+	XMLNS    string   `xml:"xmlns,attr"`
+	Sitemaps []entry  `xml:"sitemap"`
+}
+
+type entry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+func toEntries(urls []URL) []entry {
+	entries := make([]entry, len(urls))
+	for i, u := range urls {
+		entries[i] = entry{Loc: u.Loc}
+		if !u.LastMod.IsZero() {
+			entries[i].LastMod = u.LastMod.UTC().Format(time.RFC3339)
+		}
+	}
+	return entries
+}
+
+// Encode builds a sitemap listing urls.
+func Encode(urls []URL) ([]byte, error) {
+	return encode(urlset{XMLNS: namespace, URLs: toEntries(urls)})
+}
+
+// EncodeIndex builds a sitemap index listing the given sitemaps.
+func EncodeIndex(sitemaps []URL) ([]byte, error) {
+	return encode(sitemapIndex{XMLNS: namespace, Sitemaps: toEntries(sitemaps)})
+}
+
+// Split cuts urls into chunks of at most MaxURLs.
+func Split(urls []URL) [][]URL {
+	var chunks [][]URL
+	for len(urls) > MaxURLs {
+		chunks = append(chunks, urls[:MaxURLs])
+		urls = urls[MaxURLs:]
+	}
+	return append(chunks, urls)
+}
+
+// LastMod returns the most recent modification time of urls.
+func LastMod(urls []URL) time.Time {
+	var last time.Time
+	for _, u := range urls {
+		if u.LastMod.After(last) {
+			last = u.LastMod
+		}
+	}
+	return last
+}
+
+func encode(v any) ([]byte, error) {
+	out, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), out...), nil
+}
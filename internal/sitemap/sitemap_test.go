@@ -0,0 +1,54 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestSplit(t *testing.T) {
+	urls := make([]URL, MaxURLs*2+1)
+	chunks := Split(urls)
+	if len(chunks) != 3 {
+		t.Fatalf("got %d chunks; want 3", len(chunks))
+	}
+	for i, want := range []int{MaxURLs, MaxURLs, 1} {
+		if len(chunks[i]) != want {
+			t.Errorf("chunk %d has %d URLs; want %d", i, len(chunks[i]), want)
+		}
+	}
+
+	if chunks := Split(urls[:MaxURLs]); len(chunks) != 1 {
+		t.Errorf("got %d chunks of MaxURLs URLs; want 1", len(chunks))
+	}
+}
+
+func TestEncodeIndex(t *testing.T) {
+	mod := time.Date(2024, 5, 6, 7, 8, 9, 0, time.FixedZone("", 3600))
+	parts := []URL{
+		{Loc: "https://example.com/sitemap-1.xml", LastMod: mod},
+		{Loc: "https://example.com/sitemap-2.xml"},
+	}
+
+	out, err := EncodeIndex(parts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		XMLName  xml.Name `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 sitemapindex"`
+		Sitemaps []struct {
+			Loc     string `xml:"loc"`
+			LastMod string `xml:"lastmod"`
+		} `xml:"sitemap"`
+	}
+	if err := xml.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	got := fmt.Sprint(doc.Sitemaps)
+	want := "[{https://example.com/sitemap-1.xml 2024-05-06T06:08:09Z} {https://example.com/sitemap-2.xml }]"
+	if got != want {
+		t.Errorf("sitemaps = %s; want %s", got, want)
+	}
+}
@@ -0,0 +1,182 @@
+// Package socialcard draws the PNG preview images shown when a post is
+// shared on social sites.
+package socialcard
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Width and Height are the size of a card, the one Open Graph and Twitter
+// recommend for large previews.
+const (
+	Width  = 1200
+	Height = 630
+)
+
+// margin is the space left around the content of a card.
+const margin = 80
+
+// maxTitleLines bounds the title, which is cut with an ellipsis past it.
+const maxTitleLines = 4
+
+// titleSizes are the font sizes tried for the title, largest first. The
+// first one fitting the title in maxTitleLines is used.
+var titleSizes = []float64{72, 60, 52}
+
+var (
+	background = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	textColor  = color.RGBA{0x1f, 0x29, 0x37, 0xff}
+	mutedColor = color.RGBA{0x6b, 0x72, 0x80, 0xff}
+	accent     = color.RGBA{0x4f, 0x46, 0xe5, 0xff}
+)
+
+// Card is what a card shows.
+type Card struct {
+	Title string
+	Date  string
+	Site  string
+}
+
+var (
+	fontsOnce          sync.Once
+	fontsErr           error
+	boldFont, textFont *opentype.Font
+)
+
+func loadFonts() error {
+	fontsOnce.Do(func() {
+		if boldFont, fontsErr = opentype.Parse(gobold.TTF); fontsErr != nil {
+			return
+		}
+		textFont, fontsErr = opentype.Parse(goregular.TTF)
+	})
+	return fontsErr
+}
+
+// Draw returns c drawn as a PNG image.
+func Draw(c Card) ([]byte, error) {
+	if err := loadFonts(); err != nil {
+		return nil, err
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, Width, Height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(0, 0, Width, 16), image.NewUniform(accent), image.Point{}, draw.Src)
+
+	lines, titleFace, err := fitTitle(c.Title)
+	if err != nil {
+		return nil, err
+	}
+	defer titleFace.Close()
+
+	lineHeight := titleFace.Metrics().Height.Ceil() * 6 / 5
+	y := margin + titleFace.Metrics().Ascent.Ceil()
+	for _, line := range lines {
+		drawText(img, titleFace, textColor, margin, y, line)
+		y += lineHeight
+	}
+
+	footFace, err := opentype.NewFace(textFont, &opentype.FaceOptions{Size: 32, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return nil, err
+	}
+	defer footFace.Close()
+	siteFace, err := opentype.NewFace(boldFont, &opentype.FaceOptions{Size: 32, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return nil, err
+	}
+	defer siteFace.Close()
+
+	baseline := Height - margin
+	drawText(img, footFace, mutedColor, margin, baseline, c.Date)
+	siteWidth := font.MeasureString(siteFace, c.Site).Ceil()
+	drawText(img, siteFace, accent, Width-margin-siteWidth, baseline, c.Site)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// fitTitle wraps title at the largest of titleSizes where it fits, and
+// returns its lines along with the face to draw them with.
+func fitTitle(title string) ([]string, font.Face, error) {
+	words := strings.Fields(title)
+	for i, size := range titleSizes {
+		face, err := opentype.NewFace(boldFont, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+		if err != nil {
+			return nil, nil, err
+		}
+		lines := wrap(face, words, Width-2*margin)
+		if len(lines) <= maxTitleLines {
+			return lines, face, nil
+		}
+		if i == len(titleSizes)-1 {
+			lines = lines[:maxTitleLines]
+			lines[maxTitleLines-1] = ellipsize(face, lines[maxTitleLines-1], Width-2*margin)
+			return lines, face, nil
+		}
+		face.Close()
+	}
+	return nil, nil, nil
+}
+
+// wrap breaks words into lines no wider than width. Words wider than a
+// line on their own are left to overflow it.
+func wrap(face font.Face, words []string, width int) []string {
+	var lines []string
+	line := ""
+	for _, w := range words {
+		candidate := w
+		if line != "" {
+			candidate = line + " " + w
+		}
+		if line != "" && font.MeasureString(face, candidate).Ceil() > width {
+			lines = append(lines, line)
+			candidate = w
+		}
+		line = candidate
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// ellipsize shortens line, word by word, until it fits width with an
+// ellipsis added.
+func ellipsize(face font.Face, line string, width int) string {
+	for {
+		if font.MeasureString(face, line+"…").Ceil() <= width {
+			return line + "…"
+		}
+		i := strings.LastIndexByte(line, ' ')
+		if i < 0 {
+			return line + "…"
+		}
+		line = line[:i]
+	}
+}
+
+func drawText(img draw.Image, face font.Face, c color.Color, x, y int, s string) {
+	d := &font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(c),
+		Face: face,
+		Dot:  fixed.P(x, y),
+	}
+	d.DrawString(s)
+}
@@ -0,0 +1,71 @@
+// Package storage keeps uploaded files in a BlobStore, on the local disk or
+// in an S3 compatible bucket.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gochi-demo/internal/config"
+)
+
+// ErrNotFound is returned by BlobStore.Get for a missing key.
+var ErrNotFound = errors.New("storage: blob not found")
+
+// Info describes a stored blob.
+type Info struct {
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+// Object is a stored blob opened for reading.
+type Object interface {
+	io.ReadSeekCloser
+}
+
+// BlobStore stores blobs under slash separated keys such as
+// "uploads/2026/01/4f9c.png".
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get opens the blob stored under key, or returns ErrNotFound.
+	Get(ctx context.Context, key string) (Object, *Info, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// FromConfig returns the BlobStore selected by BLOB_STORE: "local", the
+// default, keeps files under MEDIA_DIR and "s3" in the S3_BUCKET bucket.
+func FromConfig() (BlobStore, error) {
+	switch strings.ToLower(config.GetConfigWithDefault("BLOB_STORE", "local")) {
+	case "local":
+		return NewLocalStore(config.GetConfigWithDefault("MEDIA_DIR", "media"))
+	case "s3":
+		return NewS3Store(S3Config{
+			Endpoint:  config.GetConfigWithDefault("S3_ENDPOINT", "s3.amazonaws.com"),
+			Region:    config.GetConfig("S3_REGION"),
+			Bucket:    config.MustGetConfig("S3_BUCKET"),
+			AccessKey: config.GetConfig("S3_ACCESS_KEY"),
+			SecretKey: config.GetConfig("S3_SECRET_KEY"),
+			UseSSL:    !strings.EqualFold(config.GetConfig("S3_USE_SSL"), "false"),
+		})
+	default:
+		return nil, fmt.Errorf("storage: unknown BLOB_STORE %q", config.GetConfig("BLOB_STORE"))
+	}
+}
+
+// validKey reports whether key is a relative, clean, slash separated path.
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return false
+		}
+	}
+	return true
+}
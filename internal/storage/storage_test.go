@@ -0,0 +1,165 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+// testStores returns a LocalStore in a temporary directory and, when
+// S3_ENDPOINT is set, an S3Store on that server, such as a local MinIO:
+//
+//	S3_ENDPOINT=localhost:9000 S3_ACCESS_KEY=minioadmin S3_SECRET_KEY=minioadmin \
+//	S3_USE_SSL=false go test ./internal/storage
+//
+// The bucket is S3_BUCKET, gochi-demo-test by default.
+func testStores(t *testing.T) map[string]BlobStore {
+	t.Helper()
+
+	local, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	stores := map[string]BlobStore{"local": local}
+
+	if endpoint := os.Getenv("S3_ENDPOINT"); endpoint != "" {
+		bucket := os.Getenv("S3_BUCKET")
+		if bucket == "" {
+			bucket = "gochi-demo-test"
+		}
+		s3, err := NewS3Store(S3Config{
+			Endpoint:  endpoint,
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    bucket,
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			UseSSL:    !strings.EqualFold(os.Getenv("S3_USE_SSL"), "false"),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		stores["s3"] = s3
+	}
+	return stores
+}
+
+// testKey returns a key no other run uses, as buckets outlive tests.
+func testKey(t *testing.T, name string) string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return "test/" + hex.EncodeToString(b) + "/" + name
+}
+
+func TestBlobStore(t *testing.T) {
+	ctx := context.Background()
+	data := []byte("hello, blob store")
+
+	for name, st := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			key := testKey(t, "hello.txt")
+			if err := st.Put(ctx, key, bytes.NewReader(data), int64(len(data)), "text/plain; charset=utf-8"); err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { st.Delete(ctx, key) })
+
+			obj, info, err := st.Get(ctx, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer obj.Close()
+			if info.Size != int64(len(data)) {
+				t.Errorf("Size = %d; want %d", info.Size, len(data))
+			}
+			if info.ContentType != "text/plain; charset=utf-8" {
+				t.Errorf("ContentType = %q; want text/plain", info.ContentType)
+			}
+			if info.ModTime.IsZero() {
+				t.Error("ModTime is zero")
+			}
+			got, err := io.ReadAll(obj)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Errorf("read %q; want %q", got, data)
+			}
+
+			// Range requests seek into objects.
+			if _, err := obj.Seek(7, io.SeekStart); err != nil {
+				t.Fatal(err)
+			}
+			if got, _ := io.ReadAll(obj); string(got) != "blob store" {
+				t.Errorf("read %q after seeking; want %q", got, "blob store")
+			}
+
+			if err := st.Delete(ctx, key); err != nil {
+				t.Fatal(err)
+			}
+			if _, _, err := st.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Get after Delete = %v; want ErrNotFound", err)
+			}
+			if err := st.Delete(ctx, key); err != nil {
+				t.Errorf("Delete of a missing blob = %v; want nil", err)
+			}
+		})
+	}
+}
+
+func TestBlobStoreGetMissing(t *testing.T) {
+	for name, st := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			if _, _, err := st.Get(context.Background(), testKey(t, "missing.png")); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Get = %v; want ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestBlobStoreInvalidKeys(t *testing.T) {
+	ctx := context.Background()
+	keys := []string{"", "../escape.txt", "a/../../escape.txt", "a/./b.txt", "/abs.txt", "a//b.txt", `a\b.txt`, "dir/"}
+
+	for name, st := range testStores(t) {
+		for _, key := range keys {
+			t.Run(name+"/"+key, func(t *testing.T) {
+				if err := st.Put(ctx, key, strings.NewReader("x"), 1, "text/plain"); err == nil {
+					st.Delete(ctx, key)
+					t.Errorf("Put(%q) succeeded", key)
+				}
+				if _, _, err := st.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+					t.Errorf("Get(%q) = %v; want ErrNotFound", key, err)
+				}
+			})
+		}
+	}
+}
+
+func TestValidKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"uploads/2026/01/4f9c.png", true},
+		{"a.txt", true},
+		{"..", false},
+		{"../a.txt", false},
+		{"a/../b.txt", false},
+		{"a/..", false},
+		{".", false},
+		{"/a.txt", false},
+		{"a//b.txt", false},
+		{`a\..\b.txt`, false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := validKey(tt.key); got != tt.want {
+			t.Errorf("validKey(%q) = %v; want %v", tt.key, got, tt.want)
+		}
+	}
+}
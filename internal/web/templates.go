@@ -0,0 +1,82 @@
+package web
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+const layoutFile = "layout.html"
+
+// Templates holds one template set per page in templates/. Every page is
+// parsed together with layout.html and the "_" prefixed partials, so each
+// page can define its own "title" and "content" blocks.
+type Templates struct {
+	pages map[string]*template.Template
+}
+
+// ParseTemplates parses the embedded templates, making funcs available to
+// all of them.
+func ParseTemplates(funcs template.FuncMap) (*Templates, error) {
+	files, err := fs.Glob(FS, "templates/*.html")
+	if err != nil {
+		return nil, err
+	}
+
+	base := template.New(layoutFile).Funcs(funcs)
+	var pages []string
+	for _, f := range files {
+		name := path.Base(f)
+		if name == layoutFile || strings.HasPrefix(name, "_") {
+			if _, err := base.ParseFS(FS, f); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		pages = append(pages, f)
+	}
+
+	t := &Templates{pages: map[string]*template.Template{}}
+	for _, f := range pages {
+		page, err := base.Clone()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := page.ParseFS(FS, f); err != nil {
+			return nil, err
+		}
+		t.pages[path.Base(f)] = page
+	}
+
+	return t, nil
+}
+
+// MustParseTemplates is like ParseTemplates but panics on error.
+func MustParseTemplates(funcs template.FuncMap) *Templates {
+	t, err := ParseTemplates(funcs)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// ExecuteTemplate renders the page called name. The output is buffered so
+// a failing template never leaves a half written page behind.
+func (t *Templates) ExecuteTemplate(w io.Writer, name string, data any) error {
+	page, ok := t.pages[name]
+	if !ok {
+		return fmt.Errorf("template %q not found", name)
+	}
+
+	var buf bytes.Buffer
+	if err := page.ExecuteTemplate(&buf, name, data); err != nil {
+		return err
+	}
+
+	_, err := buf.WriteTo(w)
+	return err
+}